@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+	"github.com/morningconsult/go-samplifyapi-client/lib/spec"
+)
+
+func init() {
+	register(&group{
+		name:    "plan",
+		summary: "show the changes needed to bring a project to a YAML or JSON spec",
+		commands: []*command{
+			{summary: "show the plan for a spec", flags: flagFile, run: withClient(planSpec)},
+		},
+	})
+	register(&group{
+		name:    "apply",
+		summary: "create or update a project from a YAML or JSON spec",
+		commands: []*command{
+			{summary: "apply a spec", flags: flagFile, run: withClient(applySpec)},
+		},
+	})
+}
+
+func (env *environment) readSpec() (*spec.Spec, error) {
+	if env.file != "-" {
+		return spec.Load(env.file)
+	}
+	b, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, err
+	}
+	return spec.Parse(b)
+}
+
+func (env *environment) printPlan(plan *spec.Plan) error {
+	if env.output == formatTable {
+		return plan.WriteDiff(env.stdout)
+	}
+	tbl := &table{header: []string{"op", "path", "old", "new", "blocked"}}
+	for _, c := range plan.Changes {
+		tbl.add(string(c.Op), c.Path, c.Old, c.New, c.Blocked)
+	}
+	return env.print(plan, tbl)
+}
+
+func planSpec(env *environment, c *samplify.Client) error {
+	s, err := env.readSpec()
+	if err != nil {
+		return err
+	}
+	plan, err := spec.NewPlanWithContext(env.ctx, c, s)
+	if err != nil {
+		return err
+	}
+	return env.printPlan(plan)
+}
+
+func applySpec(env *environment, c *samplify.Client) error {
+	s, err := env.readSpec()
+	if err != nil {
+		return err
+	}
+	plan, err := spec.NewPlanWithContext(env.ctx, c, s)
+	if err != nil {
+		return err
+	}
+	if err := env.printPlan(plan); err != nil {
+		return err
+	}
+	if plan.IsEmpty() {
+		return nil
+	}
+	if err := spec.ApplyWithContext(env.ctx, c, plan); err != nil {
+		return err
+	}
+	fmt.Fprintf(env.stderr, "applied %d change(s) to project %s\n", len(plan.Changes), plan.ExtProjectID)
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+)
+
+// Flag sets that a command accepts in addition to the common flags.
+const (
+	flagQuery = 1 << iota
+	flagFile
+	flagOut
+)
+
+// command is a leaf command such as `projects get`.
+type command struct {
+	name    string
+	args    string
+	summary string
+	// nargs is the number of positional arguments the command requires.
+	nargs int
+	flags int
+	run   func(env *environment) error
+}
+
+// group is a set of commands operating on the same resource.
+type group struct {
+	name     string
+	summary  string
+	commands []*command
+}
+
+// find returns the command named by the first argument. A group with a single unnamed command matches any arguments.
+func (g *group) find(args []string) (*command, []string) {
+	for _, c := range g.commands {
+		if c.name == "" {
+			return c, args
+		}
+		if len(args) > 0 && c.name == args[0] {
+			return c, args[1:]
+		}
+	}
+	return nil, nil
+}
+
+var groups []*group
+
+func register(g *group) {
+	groups = append(groups, g)
+}
+
+func findGroup(name string) *group {
+	name = strings.ToLower(name)
+	for _, g := range groups {
+		if g.name == name {
+			return g
+		}
+	}
+	return nil
+}
@@ -0,0 +1,432 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+func init() {
+	register(&group{
+		name:    "feasibility",
+		summary: "show the feasibility and pricing of the line items of a project",
+		commands: []*command{
+			{args: "<extProjectId>", nargs: 1, summary: "show feasibility", flags: flagQuery, run: withClient(getFeasibility)},
+		},
+	})
+	register(&group{
+		name:    "reports",
+		summary: "show project and line item reports",
+		commands: []*command{
+			{name: "project", args: "<extProjectId>", nargs: 1, summary: "show the detailed report of a project", run: withClient(getProjectReport)},
+			{name: "summary", args: "<extProjectId>", nargs: 1, summary: "show the summary report of a project", run: withClient(getProjectSummaryReport)},
+			{name: "lineitem", args: "<extProjectId> <extLineItemId>", nargs: 2, summary: "show the detailed report of a line item with quota cell stats", run: withClient(getLineItemReport)},
+		},
+	})
+	register(&group{
+		name:    "events",
+		summary: "list, accept and reject events",
+		commands: []*command{
+			{name: "list", summary: "list events", flags: flagQuery, run: withClient(listEvents)},
+			{name: "get", args: "<eventId>", nargs: 1, summary: "show an event", run: withClient(getEvent)},
+			{name: "accept", args: "<eventId>", nargs: 1, summary: "accept an event", run: withClient(acceptEvent)},
+			{name: "reject", args: "<eventId>", nargs: 1, summary: "reject an event", run: withClient(rejectEvent)},
+		},
+	})
+	register(&group{
+		name:    "templates",
+		summary: "manage quota plan templates",
+		commands: []*command{
+			{name: "list", args: "<country> <language>", nargs: 2, summary: "list templates", flags: flagQuery, run: withClient(listTemplates)},
+			{name: "create", summary: "create a template from a TemplateCriteria JSON file", flags: flagFile, run: withClient(createTemplate)},
+			{name: "update", args: "<id>", nargs: 1, summary: "update a template from a TemplateCriteria JSON file", flags: flagFile, run: withClient(updateTemplate)},
+			{name: "delete", args: "<id>", nargs: 1, summary: "delete a template", run: withClient(deleteTemplate)},
+		},
+	})
+	register(&group{
+		name:    "countries",
+		summary: "list supported countries and languages",
+		commands: []*command{
+			{summary: "list countries", flags: flagQuery, run: withClient(listCountries)},
+		},
+	})
+	register(&group{
+		name:    "attributes",
+		summary: "list the attributes of a country and language",
+		commands: []*command{
+			{args: "<country> <language>", nargs: 2, summary: "list attributes", flags: flagQuery, run: withClient(listAttributes)},
+		},
+	})
+	register(&group{
+		name:    "users",
+		summary: "show users, teams and roles",
+		commands: []*command{
+			{name: "info", summary: "show the current user", run: withClient(userInfo)},
+			{name: "list", summary: "list the users of the company", run: withClient(listUsers)},
+			{name: "teams", summary: "list the teams of the company", run: withClient(listTeams)},
+			{name: "roles", summary: "list roles", flags: flagQuery, run: withClient(listRoles)},
+		},
+	})
+	register(&group{
+		name:    "permissions",
+		summary: "show and change project permissions",
+		commands: []*command{
+			{name: "get", args: "<extProjectId>", nargs: 1, summary: "show the permissions of a project", run: withClient(getPermissions)},
+			{name: "upsert", summary: "change permissions from an UpsertPermissionsCriteria JSON file", flags: flagFile, run: withClient(upsertPermissions)},
+		},
+	})
+	register(&group{
+		name:    "invoices",
+		summary: "download invoices and show invoice summaries",
+		commands: []*command{
+			{name: "get", args: "<extProjectId>", nargs: 1, summary: "download the invoice of a project", flags: flagOut, run: withClient(getInvoice)},
+			{name: "summary", summary: "list invoice summaries", flags: flagQuery, run: withClient(listInvoiceSummaries)},
+		},
+	})
+}
+
+func getFeasibility(env *environment, c *samplify.Client) error {
+	res, err := c.GetFeasibilityWithContext(env.ctx, env.args[0], env.query)
+	if err != nil {
+		return err
+	}
+	tbl := &table{header: []string{"extLineItemId", "status", "feasible", "totalCount", "costPerInterview", "currency", "estimatedCost"}}
+	for _, f := range res.List {
+		if f.Feasibility == nil {
+			tbl.add(f.ExtLineItemID, "", "", "", "", f.Quote.Currency, formatFloat(f.Quote.EstimatedCost))
+			continue
+		}
+		tbl.add(f.ExtLineItemID, string(f.Feasibility.Status), strconv.FormatBool(f.Feasibility.Feasible),
+			strconv.FormatInt(f.Feasibility.TotalCount, 10), formatFloat(f.Feasibility.CostPerInterview),
+			f.Feasibility.Currency, formatFloat(f.Quote.EstimatedCost))
+	}
+	return env.print(res.List, tbl)
+}
+
+func statsRow(id, title string, state samplify.State, s samplify.DetailedStats, cost *samplify.Cost) []string {
+	row := []string{id, title, state.String(), strconv.FormatInt(s.Attempts, 10), strconv.FormatInt(s.Completes, 10),
+		strconv.FormatInt(s.Screenouts, 10), strconv.FormatInt(s.Overquotas, 10), strconv.FormatInt(s.RemainingCompletes, 10),
+		formatFloat(s.IncidenceRate), formatFloat(s.Conversion)}
+	if cost != nil {
+		row = append(row, cost.Currency, formatFloat(cost.EstimatedCost), formatFloat(cost.IncurredCost))
+	}
+	return row
+}
+
+var statsHeader = []string{"id", "title", "state", "attempts", "completes", "screenouts", "overquotas", "remaining", "incidence", "conversion"}
+
+func getProjectReport(env *environment, c *samplify.Client) error {
+	res, err := c.GetDetailedProjectReportWithContext(env.ctx, env.args[0])
+	if err != nil {
+		return err
+	}
+	r := res.Report
+	tbl := &table{header: append(append([]string{}, statsHeader...), "currency", "estimatedCost", "incurredCost")}
+	tbl.add(statsRow(r.ExtProjectID, r.Title, r.State, r.Stats, &r.Cost)...)
+	for _, l := range r.LineItems {
+		tbl.add(statsRow(l.ExtLineItemID, l.Title, l.State, l.Stats, &l.Cost)...)
+	}
+	return env.print(r, tbl)
+}
+
+func getProjectSummaryReport(env *environment, c *samplify.Client) error {
+	res, err := c.GetProjectReportWithContext(env.ctx, env.args[0])
+	if err != nil {
+		return err
+	}
+	return env.print(res.Report, nil)
+}
+
+func getLineItemReport(env *environment, c *samplify.Client) error {
+	res, err := c.GetDetailedLineItemReportWithContext(env.ctx, env.args[0], env.args[1])
+	if err != nil {
+		return err
+	}
+	r := res.Report
+	tbl := &table{header: statsHeader}
+	tbl.add(statsRow(r.ExtLineItemID, r.Title, r.State, r.Stats, nil)...)
+	for _, g := range r.QuotaGroups {
+		tbl.add(statsRow(g.QuotaGroupID, "", "", g.Stats, nil)...)
+		for _, qc := range g.QuotaCells {
+			tbl.add(statsRow(qc.QuotaCellID, quotaNodesText(qc.QuotaNodes), "", qc.Stats, nil)...)
+		}
+	}
+	return env.print(r, tbl)
+}
+
+func quotaNodesText(nodes []*samplify.QuotaNode) string {
+	parts := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		parts = append(parts, n.AttributeID+"="+strings.Join(n.Options, "|"))
+	}
+	return strings.Join(parts, " ")
+}
+
+func eventsTable(events []*samplify.Event) *table {
+	tbl := &table{header: []string{"eventId", "type", "extProjectId", "extLineItemId", "createdAt", "accept", "reject"}}
+	for _, e := range events {
+		accept, reject := "", ""
+		if e.Actions != nil {
+			accept, reject = e.Actions.AcceptURL, e.Actions.RejectURL
+		}
+		tbl.add(strconv.FormatInt(e.EventID, 10), string(e.EventType), e.ExtProjectID, e.ExtLineItemID,
+			formatTime(&e.CreatedAt), accept, reject)
+	}
+	return tbl
+}
+
+func listEvents(env *environment, c *samplify.Client) error {
+	res, err := c.GetEventsWithContext(env.ctx, env.query)
+	if err != nil {
+		return err
+	}
+	return env.print(res.List, eventsTable(res.List))
+}
+
+func getEvent(env *environment, c *samplify.Client) error {
+	res, err := c.GetEventByWithContext(env.ctx, env.args[0])
+	if err != nil {
+		return err
+	}
+	return env.print(res.Event, eventsTable([]*samplify.Event{res.Event}))
+}
+
+func eventAction(env *environment, c *samplify.Client, accept bool) error {
+	res, err := c.GetEventByWithContext(env.ctx, env.args[0])
+	if err != nil {
+		return err
+	}
+	if res.Event == nil {
+		return samplify.ErrEventActionNotApplicable
+	}
+	if accept {
+		err = c.AcceptEventWithContext(env.ctx, res.Event)
+	} else {
+		err = c.RejectEventWithContext(env.ctx, res.Event)
+	}
+	if err != nil {
+		return err
+	}
+	return env.print(res.Event, eventsTable([]*samplify.Event{res.Event}))
+}
+
+func acceptEvent(env *environment, c *samplify.Client) error {
+	return eventAction(env, c, true)
+}
+
+func rejectEvent(env *environment, c *samplify.Client) error {
+	return eventAction(env, c, false)
+}
+
+func templatesTable(templates []*samplify.TemplateData) *table {
+	tbl := &table{header: []string{"id", "name", "state", "country", "language", "editable", "tags"}}
+	for _, t := range templates {
+		if t == nil {
+			continue
+		}
+		country, language := "", ""
+		if t.CountryISOCode != nil {
+			country = *t.CountryISOCode
+		}
+		if t.LanguageISOCode != nil {
+			language = *t.LanguageISOCode
+		}
+		tbl.add(strconv.Itoa(t.ID), t.Name, t.State, country, language, strconv.FormatBool(t.Editable), strings.Join(t.Tags, ","))
+	}
+	return tbl
+}
+
+func listTemplates(env *environment, c *samplify.Client) error {
+	res, err := c.GetTemplateListWithContext(env.ctx, env.args[0], env.args[1], env.query)
+	if err != nil {
+		return err
+	}
+	return env.print(res.Data, templatesTable(res.Data))
+}
+
+func createTemplate(env *environment, c *samplify.Client) error {
+	criteria := &samplify.TemplateCriteria{}
+	if err := env.readInput(criteria); err != nil {
+		return err
+	}
+	res, err := c.CreateTemplateWithContext(env.ctx, criteria)
+	if err != nil {
+		return err
+	}
+	return env.print(res.Data, templatesTable([]*samplify.TemplateData{res.Data}))
+}
+
+func updateTemplate(env *environment, c *samplify.Client) error {
+	id, err := strconv.Atoi(env.args[0])
+	if err != nil {
+		return err
+	}
+	criteria := &samplify.TemplateCriteria{}
+	if err := env.readInput(criteria); err != nil {
+		return err
+	}
+	res, err := c.UpdateTemplateWithContext(env.ctx, id, criteria)
+	if err != nil {
+		return err
+	}
+	return env.print(res.Data, templatesTable([]*samplify.TemplateData{res.Data}))
+}
+
+func deleteTemplate(env *environment, c *samplify.Client) error {
+	id, err := strconv.Atoi(env.args[0])
+	if err != nil {
+		return err
+	}
+	res, err := c.DeleteTemplateWithContext(env.ctx, id)
+	if err != nil {
+		return err
+	}
+	return env.print(res.Status, nil)
+}
+
+func listCountries(env *environment, c *samplify.Client) error {
+	res, err := c.GetCountriesWithContext(env.ctx, env.query)
+	if err != nil {
+		return err
+	}
+	tbl := &table{header: []string{"isoCode", "name", "languages"}}
+	for _, country := range res.List {
+		languages := make([]string, 0, len(country.SupportedLanguages))
+		for _, l := range country.SupportedLanguages {
+			languages = append(languages, l.IsoCode)
+		}
+		tbl.add(country.IsoCode, country.CountryName, strings.Join(languages, ","))
+	}
+	return env.print(res.List, tbl)
+}
+
+func listAttributes(env *environment, c *samplify.Client) error {
+	res, err := c.GetAttributesWithContext(env.ctx, env.args[0], env.args[1], env.query)
+	if err != nil {
+		return err
+	}
+	tbl := &table{header: []string{"id", "name", "text", "type", "state", "filters", "quotas", "options"}}
+	for _, a := range res.List {
+		options := make([]string, 0, len(a.Options))
+		for _, o := range a.Options {
+			options = append(options, o.ID+"="+o.Text)
+		}
+		tbl.add(a.ID, a.Name, a.Text, a.Type, string(a.State), strconv.FormatBool(a.IsAllowedInFilters),
+			strconv.FormatBool(a.IsAllowedInQuotas), strings.Join(options, "; "))
+	}
+	return env.print(res.List, tbl)
+}
+
+func userInfo(env *environment, c *samplify.Client) error {
+	res, err := c.GetUserInfoWithContext(env.ctx)
+	if err != nil {
+		return err
+	}
+	return env.print(res.User, nil)
+}
+
+func listUsers(env *environment, c *samplify.Client) error {
+	res, err := c.CompanyUsersWithContext(env.ctx)
+	if err != nil {
+		return err
+	}
+	tbl := &table{header: []string{"username", "name", "email"}}
+	for _, u := range res.List {
+		tbl.add(u.Username, u.FullName, u.Email)
+	}
+	return env.print(res.List, tbl)
+}
+
+func listTeams(env *environment, c *samplify.Client) error {
+	res, err := c.TeamsInfoWithContext(env.ctx)
+	if err != nil {
+		return err
+	}
+	tbl := &table{header: []string{"id", "name", "status", "default", "description"}}
+	for _, t := range res.List {
+		tbl.add(strconv.Itoa(int(t.ID)), t.Name, t.Status, strconv.FormatBool(t.Default), t.Description)
+	}
+	return env.print(res.List, tbl)
+}
+
+func listRoles(env *environment, c *samplify.Client) error {
+	res, err := c.RolesWithContext(env.ctx, env.query)
+	if err != nil {
+		return err
+	}
+	tbl := &table{header: []string{"id", "name", "description", "assignableRoles"}}
+	for _, r := range res.Roles {
+		tbl.add(r.ID, r.Name, r.Description, strings.Join(r.AssignableRoles, ","))
+	}
+	return env.print(res.Roles, tbl)
+}
+
+func permissionsTable(p *samplify.ProjectPermissions) *table {
+	tbl := &table{header: []string{"type", "id", "name", "role"}}
+	if p == nil {
+		return tbl
+	}
+	for _, u := range p.Users {
+		tbl.add("user", strconv.Itoa(int(u.ID)), u.Username, u.Role)
+	}
+	for _, t := range p.Teams {
+		tbl.add("team", strconv.Itoa(int(t.ID)), t.Name, "")
+	}
+	return tbl
+}
+
+func getPermissions(env *environment, c *samplify.Client) error {
+	res, err := c.ProjectPermissionsWithContext(env.ctx, env.args[0])
+	if err != nil {
+		return err
+	}
+	return env.print(res.ProjectPermissions, permissionsTable(res.ProjectPermissions))
+}
+
+func upsertPermissions(env *environment, c *samplify.Client) error {
+	criteria := &samplify.UpsertPermissionsCriteria{}
+	if err := env.readInput(criteria); err != nil {
+		return err
+	}
+	res, err := c.UpsertProjectPermissionsWithContext(env.ctx, criteria)
+	if err != nil {
+		return err
+	}
+	return env.print(res.ProjectPermissions, permissionsTable(res.ProjectPermissions))
+}
+
+func getInvoice(env *environment, c *samplify.Client) error {
+	if len(env.out) == 0 {
+		return errors.New("the file to write the invoice to must be given with --out")
+	}
+	res, err := c.GetInvoiceWithContext(env.ctx, env.args[0], nil)
+	if err != nil {
+		return err
+	}
+	invoice := &samplify.Invoice{}
+	if err := json.Unmarshal(res.Body, invoice); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(env.out, invoice.File, 0644); err != nil {
+		return err
+	}
+	tbl := &table{header: []string{"extProjectId", "file", "bytes"}}
+	tbl.add(env.args[0], env.out, strconv.Itoa(len(invoice.File)))
+	return env.print(map[string]interface{}{"extProjectId": env.args[0], "file": env.out, "bytes": len(invoice.File)}, tbl)
+}
+
+func listInvoiceSummaries(env *environment, c *samplify.Client) error {
+	res, err := c.GetInvoiceSummariesWithContext(env.ctx, env.query)
+	if err != nil {
+		return err
+	}
+	tbl := &table{header: []string{"extProjectId", "title", "jobNumber", "currency", "totalCost"}}
+	for _, s := range res.List {
+		tbl.add(s.ExtProjectID, s.Title, s.JobNumber, s.Currency, formatFloat(s.TotalCost))
+	}
+	return env.print(res.List, tbl)
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+var errHelp = errors.New("help requested")
+
+const commonFlagsUsage = `  --profile NAME    credentials profile (default "default", or $SAMPLIFY_PROFILE)
+  --config PATH     profiles file (default $HOME/.samplify/config.json, or $SAMPLIFY_CONFIG)
+  --env ENV         override the profile environment: dev, uat or prod
+  --timeout SECS    request timeout in seconds
+  -o, --output FMT  output format: json, table or csv (default "table")
+`
+
+const queryFlagsUsage = `  --filter FIELD=VALUE  filter by a top level field, repeatable. Date fields accept FROM,TO
+  --sort FIELD[:DIR]    sort by a top level field, asc or desc, repeatable
+  --limit N             page size, up to 1000
+  --offset N            number of records to skip
+  --scope SCOPE         list scope, e.g. "team" or "company"
+  --project ID          extProjectId query parameter
+  --lineitem ID         extLineItemId query parameter
+  --event-type TYPE     eventType query parameter
+`
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+// environment holds everything a command needs to run.
+type environment struct {
+	ctx     context.Context
+	args    []string
+	stdout  io.Writer
+	stderr  io.Writer
+	output  string
+	file    string
+	out     string
+	query   *samplify.QueryOptions
+	profile *profile
+	client  *samplify.Client
+}
+
+func newEnvironment(ctx context.Context, cmd *command, args []string, stdout, stderr io.Writer) (*environment, error) {
+	env := &environment{ctx: ctx, stdout: stdout, stderr: stderr}
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+
+	var (
+		profileName, configPath, envName string
+		timeout                          int
+		filters, sorts                   stringList
+		limit, offset                    uint
+		scope, extProjectID, extLineItem string
+		eventType                        string
+	)
+	fs.StringVar(&profileName, "profile", "", "")
+	fs.StringVar(&configPath, "config", "", "")
+	fs.StringVar(&envName, "env", "", "")
+	fs.IntVar(&timeout, "timeout", 0, "")
+	fs.StringVar(&env.output, "output", formatTable, "")
+	fs.StringVar(&env.output, "o", formatTable, "")
+	if cmd.flags&flagQuery != 0 {
+		fs.Var(&filters, "filter", "")
+		fs.Var(&sorts, "sort", "")
+		fs.UintVar(&limit, "limit", 0, "")
+		fs.UintVar(&offset, "offset", 0, "")
+		fs.StringVar(&scope, "scope", "", "")
+		fs.StringVar(&extProjectID, "project", "", "")
+		fs.StringVar(&extLineItem, "lineitem", "", "")
+		fs.StringVar(&eventType, "event-type", "", "")
+	}
+	if cmd.flags&flagFile != 0 {
+		fs.StringVar(&env.file, "f", "", "")
+	}
+	if cmd.flags&flagOut != 0 {
+		fs.StringVar(&env.out, "out", "", "")
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if err == flag.ErrHelp {
+		printCommandUsage(stdout, cmd)
+		return nil, errHelp
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(positional) != cmd.nargs {
+		return nil, fmt.Errorf("usage: %s", strings.TrimSpace(cmd.name+" "+cmd.args))
+	}
+	env.args = positional
+	if !isFormat(env.output) {
+		return nil, fmt.Errorf("unknown output format %q", env.output)
+	}
+	if cmd.flags&flagFile != 0 && len(env.file) == 0 {
+		return nil, errors.New("an input file must be given with -f")
+	}
+
+	if cmd.flags&flagQuery != 0 {
+		env.query, err = queryOptions(filters, sorts, limit, offset, scope, extProjectID, extLineItem, eventType)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	env.profile, err = loadProfile(configPath, profileName)
+	if err != nil {
+		return nil, err
+	}
+	if len(envName) > 0 {
+		env.profile.Env = envName
+	}
+	if timeout > 0 {
+		env.profile.Timeout = timeout
+	}
+	return env, nil
+}
+
+func printCommandUsage(w io.Writer, cmd *command) {
+	fmt.Fprintf(w, "%s\n\n", cmd.summary)
+	fmt.Fprint(w, "Flags:\n", commonFlagsUsage)
+	if cmd.flags&flagQuery != 0 {
+		fmt.Fprint(w, queryFlagsUsage)
+	}
+	if cmd.flags&flagFile != 0 {
+		fmt.Fprintln(w, "  -f PATH           JSON input file, - for stdin")
+	}
+	if cmd.flags&flagOut != 0 {
+		fmt.Fprintln(w, "  --out PATH        file to write the downloaded document to")
+	}
+}
+
+// parseInterspersed parses flags that may appear before, between or after the positional arguments.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		if args[0] == "--" {
+			return append(positional, args[1:]...), nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// dateFields are the query fields that take a FROM,TO date range.
+var dateFields = map[samplify.QueryField]bool{
+	samplify.QueryFieldCreatedAt:          true,
+	samplify.QueryFieldUpdatedAt:          true,
+	samplify.QueryFieldLaunchedAt:         true,
+	samplify.QueryFieldStateLastUpdatedAt: true,
+}
+
+func queryOptions(filters, sorts []string, limit, offset uint, scope, extProjectID, extLineItemID, eventType string) (*samplify.QueryOptions, error) {
+	options := &samplify.QueryOptions{Limit: limit, Offset: offset, Scope: scope}
+	for _, f := range filters {
+		parts := strings.SplitN(f, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			return nil, fmt.Errorf("invalid filter %q, expected FIELD=VALUE", f)
+		}
+		field := samplify.QueryField(parts[0])
+		var value samplify.Value = samplify.FilterValue{Value: parts[1]}
+		if dateFields[field] {
+			dv, err := parseDateRange(parts[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter %q: %v", f, err)
+			}
+			value = dv
+		}
+		options.FilterBy = append(options.FilterBy, &samplify.Filter{Field: field, Value: value})
+	}
+	for _, s := range sorts {
+		parts := strings.SplitN(s, ":", 2)
+		direction := samplify.SortDirectionAsc
+		if len(parts) == 2 {
+			direction = samplify.SortDirection(strings.ToLower(parts[1]))
+		}
+		if direction != samplify.SortDirectionAsc && direction != samplify.SortDirectionDesc {
+			return nil, fmt.Errorf("invalid sort %q, direction must be asc or desc", s)
+		}
+		options.SortBy = append(options.SortBy, &samplify.Sort{Field: samplify.QueryField(parts[0]), Direction: direction})
+	}
+	if len(extProjectID) > 0 {
+		options.ExtProjectId = &extProjectID
+	}
+	if len(extLineItemID) > 0 {
+		options.ExtLineItemId = &extLineItemID
+	}
+	if len(eventType) > 0 {
+		options.EventType = &eventType
+	}
+	return options, nil
+}
+
+func parseDateRange(v string) (samplify.DateFilterValue, error) {
+	var dv samplify.DateFilterValue
+	parts := strings.SplitN(v, ",", 2)
+	dates := []**time.Time{&dv.From, &dv.To}
+	for i, p := range parts {
+		p = strings.TrimSpace(p)
+		if len(p) == 0 {
+			continue
+		}
+		t, err := time.Parse("2006-01-02", strings.Replace(p, "/", "-", -1))
+		if err != nil {
+			return dv, err
+		}
+		*dates[i] = &t
+	}
+	return dv, nil
+}
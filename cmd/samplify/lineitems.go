@@ -0,0 +1,130 @@
+package main
+
+import (
+	"strconv"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+func init() {
+	register(&group{
+		name:    "lineitems",
+		summary: "list, inspect and change the state of line items",
+		commands: []*command{
+			{name: "list", args: "<extProjectId>", nargs: 1, summary: "list the line items of a project", flags: flagQuery, run: withClient(listLineItems)},
+			{name: "get", args: "<extProjectId> <extLineItemId>", nargs: 2, summary: "show a line item", run: withClient(getLineItem)},
+			{name: "launch", args: "<extProjectId> <extLineItemId>", nargs: 2, summary: "launch a line item", run: lineItemAction(samplify.ActionLaunched)},
+			{name: "pause", args: "<extProjectId> <extLineItemId>", nargs: 2, summary: "pause a line item", run: lineItemAction(samplify.ActionPaused)},
+			{name: "close", args: "<extProjectId> <extLineItemId>", nargs: 2, summary: "close a line item", run: lineItemAction(samplify.ActionClosed)},
+		},
+	})
+	register(&group{
+		name:    "bulk",
+		summary: "change the state of every line item of the projects matching the query",
+		commands: []*command{
+			{name: "launch", summary: "launch the line items", flags: flagQuery, run: bulkAction(samplify.ActionLaunched)},
+			{name: "pause", summary: "pause the line items", flags: flagQuery, run: bulkAction(samplify.ActionPaused)},
+			{name: "close", summary: "close the line items", flags: flagQuery, run: bulkAction(samplify.ActionClosed)},
+		},
+	})
+	register(&group{
+		name:    "quotacells",
+		summary: "pause and launch quota cells",
+		commands: []*command{
+			{name: "pause", args: "<extProjectId> <extLineItemId> <quotaCellId>", nargs: 3, summary: "pause a quota cell", run: quotaCellAction(samplify.ActionPaused)},
+			{name: "launch", args: "<extProjectId> <extLineItemId> <quotaCellId>", nargs: 3, summary: "launch a quota cell", run: quotaCellAction(samplify.ActionLaunched)},
+		},
+	})
+}
+
+func lineItemsTable(p *samplify.Project) *table {
+	tbl := &table{header: []string{"extProjectId", "extLineItemId", "title", "country", "language", "state", "stateReason", "loi", "incidence"}}
+	if p == nil {
+		return tbl
+	}
+	for _, l := range p.LineItems {
+		tbl.add(p.ExtProjectID, l.ExtLineItemID, l.Title, l.CountryISOCode, l.LanguageISOCode, l.State.String(),
+			l.StateReason, strconv.FormatInt(l.LengthOfInterview, 10), formatFloat(l.IndicativeIncidence))
+	}
+	return tbl
+}
+
+func listLineItems(env *environment, c *samplify.Client) error {
+	res, err := c.GetAllLineItemsWithContext(env.ctx, env.args[0], env.query)
+	if err != nil {
+		return err
+	}
+	tbl := &table{header: []string{"extLineItemId", "title", "country", "language", "state", "stateReason", "launchedAt"}}
+	for _, l := range res.List {
+		tbl.add(l.ExtLineItemID, l.Title, l.CountryISOCode, l.LanguageISOCode, l.State.String(), l.StateReason, formatTime(l.LaunchedAt))
+	}
+	return env.print(res.List, tbl)
+}
+
+func getLineItem(env *environment, c *samplify.Client) error {
+	res, err := c.GetLineItemByWithContext(env.ctx, env.args[0], env.args[1])
+	if err != nil {
+		return err
+	}
+	return env.print(res.Item, nil)
+}
+
+func lineItemAction(action samplify.Action) func(env *environment) error {
+	return withClient(func(env *environment, c *samplify.Client) error {
+		res, err := c.UpdateLineItemStateWithContext(env.ctx, env.args[0], env.args[1], action)
+		if err != nil {
+			return err
+		}
+		tbl := &table{header: []string{"extLineItemId", "state", "stateReason"}}
+		if res.LineItem != nil {
+			tbl.add(res.LineItem.ExtLineItemID, res.LineItem.State.String(), res.LineItem.StateReason)
+		}
+		return env.print(res.LineItem, tbl)
+	})
+}
+
+// bulkAction prints the report and fails if any line item could not be changed.
+func bulkAction(action samplify.Action) func(env *environment) error {
+	return withClient(func(env *environment, c *samplify.Client) error {
+		report, err := c.BulkUpdateLineItemStateByQueryWithContext(env.ctx, env.query, action, nil)
+		if err != nil {
+			return err
+		}
+		tbl := &table{header: []string{"extProjectId", "extLineItemId", "status", "from", "to", "error"}}
+		items := make([]map[string]string, 0, len(report.Items))
+		for _, i := range report.Items {
+			msg := ""
+			if i.Err != nil {
+				msg = i.Err.Error()
+			}
+			tbl.add(i.ExtProjectID, i.ExtLineItemID, string(i.Status), i.From.String(), i.To.String(), msg)
+			items = append(items, map[string]string{
+				"extProjectId": i.ExtProjectID, "extLineItemId": i.ExtLineItemID, "status": string(i.Status),
+				"from": i.From.String(), "to": i.To.String(), "error": msg,
+			})
+		}
+		if err := env.print(items, tbl); err != nil {
+			return err
+		}
+		return report.Err()
+	})
+}
+
+func quotaCellAction(action samplify.Action) func(env *environment) error {
+	return withClient(func(env *environment, c *samplify.Client) error {
+		res, err := c.SetQuotaCellStatusWithContext(env.ctx, env.args[0], env.args[1], env.args[2], action)
+		if err != nil {
+			return err
+		}
+		tbl := &table{header: []string{"quotaCellId", "status"}}
+		id, status := env.args[2], ""
+		if res.QuotaCell.QuotaCellID != nil {
+			id = *res.QuotaCell.QuotaCellID
+		}
+		if res.QuotaCell.Status != nil {
+			status = string(*res.QuotaCell.Status)
+		}
+		tbl.add(id, status)
+		return env.print(res.QuotaCell, tbl)
+	})
+}
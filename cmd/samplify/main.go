@@ -0,0 +1,100 @@
+// Command samplify is a command-line interface to the Samplify API.
+//
+// Usage:
+//
+//	samplify [global flags] <group> <command> [flags] [arguments]
+//
+// Run `samplify help` for the list of commands.
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+func main() {
+	ctx, cancel := context.WithCancel(context.Background())
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	go func() {
+		<-sig
+		cancel()
+	}()
+	os.Exit(run(ctx, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command line and returns the process exit code.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(stdout, args)
+		return 0
+	}
+	g := findGroup(args[0])
+	if g == nil {
+		fmt.Fprintf(stderr, "samplify: unknown command %q\n", args[0])
+		printUsage(stderr, nil)
+		return 2
+	}
+	cmd, rest := g.find(args[1:])
+	if cmd == nil {
+		fmt.Fprintf(stderr, "samplify: unknown %s command\n", g.name)
+		printGroupUsage(stderr, g)
+		return 2
+	}
+	env, err := newEnvironment(ctx, cmd, rest, stdout, stderr)
+	if err == errHelp {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "samplify: %v\n", err)
+		return 2
+	}
+	if err := cmd.run(env); err != nil {
+		fmt.Fprintf(stderr, "samplify: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func printUsage(w io.Writer, args []string) {
+	if len(args) > 1 {
+		if g := findGroup(args[1]); g != nil {
+			printGroupUsage(w, g)
+			return
+		}
+	}
+	fmt.Fprintln(w, "Usage: samplify <group> <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Groups:")
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	names := make([]string, 0, len(groups))
+	for _, g := range groups {
+		names = append(names, g.name)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		fmt.Fprintf(tw, "  %s\t%s\n", n, findGroup(n).summary)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run `samplify help <group>` for the commands of a group.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Common flags:")
+	fmt.Fprintln(w, strings.TrimRight(commonFlagsUsage, "\n"))
+}
+
+func printGroupUsage(w io.Writer, g *group) {
+	fmt.Fprintf(w, "%s - %s\n\nCommands:\n", g.name, g.summary)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, c := range g.commands {
+		usage := strings.TrimSpace(fmt.Sprintf("%s %s %s", g.name, c.name, c.args))
+		fmt.Fprintf(tw, "  samplify %s\t%s\n", usage, c.summary)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, url string) string {
+	dir, err := ioutil.TempDir("", "samplify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config.json")
+	cfg := fmt.Sprintf(`{"profiles":{"test":{"clientId":"c","username":"u","password":"p","apiBaseURL":%q,"authURL":%q}}}`, url, url)
+	if err := ioutil.WriteFile(path, []byte(cfg), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRun(t *testing.T) {
+	var requested []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token/password":
+			w.Write([]byte(`{"accessToken":"token","expiresIn":1800}`))
+		case "/projects":
+			requested = append(requested, r.URL.String())
+			w.Write([]byte(`{"data":[{"extProjectId":"p1","title":"First","state":"LAUNCHED"},{"extProjectId":"p2","title":"Second, again","state":"PAUSED"}]}`))
+		case "/projects/p1/lineItems/l1":
+			w.Write([]byte(`{"data":{"extLineItemId":"l1","state":"LAUNCHED"}}`))
+		case "/projects/p1/lineItems/l1/pause":
+			requested = append(requested, r.URL.String())
+			w.Write([]byte(`{"data":{"extLineItemId":"l1","state":"PAUSED"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	cfg := writeConfig(t, ts.URL)
+	defer os.RemoveAll(filepath.Dir(cfg))
+
+	tables := []struct {
+		name     string
+		args     []string
+		code     int
+		url      string
+		expected string
+	}{
+		{
+			"Case 1: list projects as csv with filters",
+			[]string{"projects", "list", "--config", cfg, "--profile", "test", "-o", "csv", "--filter", "state=LAUNCHED", "--sort", "createdAt:desc", "--limit", "5"},
+			0,
+			"/projects?state=LAUNCHED&sort=createdAt:desc&limit=5",
+			"extProjectId,title,jobNumber,state,createdAt,launchedAt\np1,First,,LAUNCHED,,\np2,\"Second, again\",,PAUSED,,\n",
+		},
+		{
+			"Case 2: flags after positional arguments",
+			[]string{"lineitems", "pause", "p1", "l1", "--config", cfg, "--profile", "test", "-o", "table"},
+			0,
+			"/projects/p1/lineItems/l1/pause",
+			"EXTLINEITEMID  STATE   STATEREASON\nl1             PAUSED  \n",
+		},
+		{
+			"Case 3: unknown group",
+			[]string{"unknown"},
+			2,
+			"",
+			"",
+		},
+		{
+			"Case 4: missing arguments",
+			[]string{"projects", "get", "--config", cfg, "--profile", "test"},
+			2,
+			"",
+			"",
+		},
+	}
+
+	for _, table := range tables {
+		requested = nil
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+		code := run(context.Background(), table.args, stdout, stderr)
+		if code != table.code {
+			t.Errorf("%s: got exit code %d, want %d: %s", table.name, code, table.code, stderr.String())
+			continue
+		}
+		if len(table.url) > 0 && (len(requested) != 1 || requested[0] != table.url) {
+			t.Errorf("%s: got requests %v, want %s", table.name, requested, table.url)
+		}
+		if len(table.expected) > 0 && stdout.String() != table.expected {
+			t.Errorf("%s: got output %q, want %q", table.name, stdout.String(), table.expected)
+		}
+	}
+}
+
+func TestQueryOptions(t *testing.T) {
+	options, err := queryOptions([]string{"createdAt=2019-01-01,2019/02/01", "title=a b"}, []string{"title"}, 0, 0, "", "p1", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(options.FilterBy) != 2 || options.FilterBy[0].Value.String() != "2019/01/01,2019/02/01" || options.FilterBy[1].Value.String() != "a+b" {
+		t.Errorf("unexpected filters %v %v", options.FilterBy[0].Value, options.FilterBy[1].Value)
+	}
+	if len(options.SortBy) != 1 || options.SortBy[0].Direction != "asc" {
+		t.Errorf("unexpected sort %+v", options.SortBy)
+	}
+	if options.ExtProjectId == nil || *options.ExtProjectId != "p1" {
+		t.Error("expected the extProjectId query parameter to be set")
+	}
+	for _, bad := range [][]string{{"novalue"}, {"createdAt=yesterday"}} {
+		if _, err := queryOptions(bad, nil, 0, 0, "", "", "", ""); err == nil || !strings.Contains(err.Error(), "invalid filter") {
+			t.Errorf("expected an invalid filter error for %v, got %v", bad, err)
+		}
+	}
+}
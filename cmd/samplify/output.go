@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+// Output formats
+const (
+	formatJSON  = "json"
+	formatTable = "table"
+	formatCSV   = "csv"
+)
+
+func isFormat(f string) bool {
+	return f == formatJSON || f == formatTable || f == formatCSV
+}
+
+// table is the tabular view of a command result.
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+func (t *table) add(values ...string) {
+	t.rows = append(t.rows, values)
+}
+
+// print writes data in the requested format. tbl is used for the table and csv formats. When it is nil a
+// FIELD/VALUE table is built from the fields of data.
+func (env *environment) print(data interface{}, tbl *table) error {
+	if env.output == formatJSON {
+		enc := json.NewEncoder(env.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(data)
+	}
+	if tbl == nil {
+		tbl = fieldsTable(data)
+	}
+	if env.output == formatCSV {
+		w := csv.NewWriter(env.stdout)
+		if err := w.Write(tbl.header); err != nil {
+			return err
+		}
+		if err := w.WriteAll(tbl.rows); err != nil {
+			return err
+		}
+		return w.Error()
+	}
+	tw := tabwriter.NewWriter(env.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(tbl.header, "\t")))
+	for _, r := range tbl.rows {
+		fmt.Fprintln(tw, strings.Join(r, "\t"))
+	}
+	return tw.Flush()
+}
+
+// fieldsTable lists the fields of a struct, nested values are rendered as compact JSON.
+func fieldsTable(data interface{}) *table {
+	tbl := &table{header: []string{"field", "value"}}
+	v := reflect.Indirect(reflect.ValueOf(data))
+	if v.Kind() != reflect.Struct {
+		tbl.add("value", fieldValue(v))
+		return tbl
+	}
+	addStructFields(tbl, v)
+	return tbl
+}
+
+func addStructFields(tbl *table, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		if f.Anonymous && reflect.Indirect(v.Field(i)).Kind() == reflect.Struct {
+			addStructFields(tbl, reflect.Indirect(v.Field(i)))
+			continue
+		}
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if len(name) == 0 {
+			name = f.Name
+		}
+		tbl.add(name, fieldValue(v.Field(i)))
+	}
+}
+
+func fieldValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return ""
+	}
+	if ct, ok := v.Interface().(samplify.CustomTime); ok {
+		return formatTime(&ct)
+	}
+	if ct, ok := v.Interface().(*samplify.CustomTime); ok {
+		return formatTime(ct)
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return ""
+		}
+		return fieldValue(v.Elem())
+	case reflect.Struct, reflect.Slice, reflect.Map, reflect.Array:
+		b, err := json.Marshal(v.Interface())
+		if err != nil {
+			return err.Error()
+		}
+		return string(b)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+func formatTime(t *samplify.CustomTime) string {
+	if t == nil || !t.IsSet() {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// readInput decodes the JSON file given with -f into v. A path of "-" reads from stdin.
+func (env *environment) readInput(v interface{}) error {
+	var (
+		b   []byte
+		err error
+	)
+	if env.file == "-" {
+		b, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		b, err = ioutil.ReadFile(env.file)
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// getClient returns the API client for the selected profile.
+func (env *environment) getClient() (*samplify.Client, error) {
+	if env.client != nil {
+		return env.client, nil
+	}
+	c, err := env.profile.newClient()
+	if err != nil {
+		return nil, err
+	}
+	env.client = c
+	return c, nil
+}
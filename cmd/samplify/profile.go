@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+const defaultProfile = "default"
+
+// profile holds the credentials and environment of a Samplify account.
+type profile struct {
+	ClientID   string `json:"clientId"`
+	Username   string `json:"username"`
+	Password   string `json:"password"`
+	Env        string `json:"env"`
+	Timeout    int    `json:"timeout,omitempty"`
+	APIBaseURL string `json:"apiBaseURL,omitempty"`
+	AuthURL    string `json:"authURL,omitempty"`
+}
+
+// config is the layout of the profiles file:
+//
+//	{"profiles": {"default": {"clientId": "...", "username": "...", "password": "...", "env": "uat"}}}
+type config struct {
+	Profiles map[string]*profile `json:"profiles"`
+}
+
+func defaultConfigPath() string {
+	if p := os.Getenv("SAMPLIFY_CONFIG"); len(p) > 0 {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".samplify", "config.json")
+}
+
+// loadProfile reads the named profile from the profiles file. A missing file is not an error as long as the
+// credentials are provided through the SAMPLIFY_CLIENT_ID, SAMPLIFY_USERNAME and SAMPLIFY_PASSWORD variables.
+func loadProfile(path, name string) (*profile, error) {
+	explicitPath := len(path) > 0
+	if !explicitPath {
+		path = defaultConfigPath()
+	}
+	if len(name) == 0 {
+		name = os.Getenv("SAMPLIFY_PROFILE")
+	}
+	explicitName := len(name) > 0
+	if !explicitName {
+		name = defaultProfile
+	}
+
+	p := &profile{}
+	b, err := ioutil.ReadFile(path)
+	if err != nil && (explicitPath || !os.IsNotExist(err)) {
+		return nil, err
+	}
+	if err == nil {
+		cfg := &config{}
+		if err := json.Unmarshal(b, cfg); err != nil {
+			return nil, fmt.Errorf("reading %s: %v", path, err)
+		}
+		found, ok := cfg.Profiles[name]
+		if !ok && explicitName {
+			return nil, fmt.Errorf("profile %q not found in %s", name, path)
+		}
+		if ok {
+			p = found
+		}
+	} else if explicitName {
+		return nil, fmt.Errorf("profile %q not found, %s does not exist", name, path)
+	}
+
+	if v := os.Getenv("SAMPLIFY_CLIENT_ID"); len(v) > 0 {
+		p.ClientID = v
+	}
+	if v := os.Getenv("SAMPLIFY_USERNAME"); len(v) > 0 {
+		p.Username = v
+	}
+	if v := os.Getenv("SAMPLIFY_PASSWORD"); len(v) > 0 {
+		p.Password = v
+	}
+	if len(p.Env) == 0 {
+		p.Env = "uat"
+	}
+	return p, nil
+}
+
+// newClient creates a client for the profile. Custom URLs take precedence over the environment.
+func (p *profile) newClient() (*samplify.Client, error) {
+	if len(p.APIBaseURL) > 0 {
+		var timeout *int
+		if p.Timeout > 0 {
+			timeout = &p.Timeout
+		}
+		return samplify.NewClient(p.ClientID, p.Username, p.Password, &samplify.ClientOptions{
+			APIBaseURL: p.APIBaseURL,
+			AuthURL:    p.AuthURL,
+			Timeout:    timeout,
+		}), nil
+	}
+	return samplify.NewClientFromEnv(p.ClientID, p.Username, p.Password, p.Env, p.Timeout)
+}
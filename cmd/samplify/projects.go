@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+func init() {
+	register(&group{
+		name:    "projects",
+		summary: "list, inspect, create and manage projects",
+		commands: []*command{
+			{name: "list", summary: "list projects", flags: flagQuery, run: withClient(listProjects)},
+			{name: "get", args: "<extProjectId>", nargs: 1, summary: "show a project", run: withClient(getProject)},
+			{name: "create", summary: "create a project from a CreateProjectCriteria JSON file", flags: flagFile, run: withClient(createProject)},
+			{name: "update", summary: "update a project from an UpdateProjectCriteria JSON file", flags: flagFile, run: withClient(updateProject)},
+			{name: "buy", args: "<extProjectId>", nargs: 1, summary: "buy a project using a JSON list of BuyProjectCriteria", flags: flagFile, run: withClient(buyProject)},
+			{name: "close", args: "<extProjectId>", nargs: 1, summary: "close a project", run: withClient(closeProject)},
+			{name: "export", args: "<extProjectId>", nargs: 1, summary: "write a snapshot archive of a project to the --out file", flags: flagOut, run: withClient(exportProject)},
+			{name: "import", summary: "create a project from a snapshot archive", flags: flagFile, run: withClient(importProject)},
+		},
+	})
+}
+
+// withClient adapts a command that needs an API client.
+func withClient(f func(env *environment, c *samplify.Client) error) func(env *environment) error {
+	return func(env *environment) error {
+		c, err := env.getClient()
+		if err != nil {
+			return err
+		}
+		return f(env, c)
+	}
+}
+
+func projectHeadersTable(projects []*samplify.ProjectHeader) *table {
+	tbl := &table{header: []string{"extProjectId", "title", "jobNumber", "state", "createdAt", "launchedAt"}}
+	for _, p := range projects {
+		tbl.add(p.ExtProjectID, p.Title, p.JobNumber, p.State.String(), formatTime(&p.CreatedAt), formatTime(p.LaunchedAt))
+	}
+	return tbl
+}
+
+func projectTable(p *samplify.Project) *table {
+	if p == nil {
+		return &table{header: []string{"extProjectId"}}
+	}
+	return projectHeadersTable([]*samplify.ProjectHeader{&p.ProjectHeader})
+}
+
+func listProjects(env *environment, c *samplify.Client) error {
+	res, err := c.GetAllProjectsWithContext(env.ctx, env.query)
+	if err != nil {
+		return err
+	}
+	return env.print(res.Projects, projectHeadersTable(res.Projects))
+}
+
+func getProject(env *environment, c *samplify.Client) error {
+	res, err := c.GetProjectByWithContext(env.ctx, env.args[0])
+	if err != nil {
+		return err
+	}
+	return env.print(res.Project, lineItemsTable(res.Project))
+}
+
+func createProject(env *environment, c *samplify.Client) error {
+	criteria := &samplify.CreateProjectCriteria{}
+	if err := env.readInput(criteria); err != nil {
+		return err
+	}
+	res, err := c.CreateProjectWithContext(env.ctx, criteria)
+	if err != nil {
+		return err
+	}
+	return env.print(res.Project, projectTable(res.Project))
+}
+
+func updateProject(env *environment, c *samplify.Client) error {
+	criteria := &samplify.UpdateProjectCriteria{}
+	if err := env.readInput(criteria); err != nil {
+		return err
+	}
+	res, err := c.UpdateProjectWithContext(env.ctx, criteria)
+	if err != nil {
+		return err
+	}
+	return env.print(res.Project, projectTable(res.Project))
+}
+
+func buyProject(env *environment, c *samplify.Client) error {
+	var criteria []*samplify.BuyProjectCriteria
+	if err := env.readInput(&criteria); err != nil {
+		return err
+	}
+	res, err := c.BuyProjectWithContext(env.ctx, env.args[0], criteria)
+	if err != nil {
+		return err
+	}
+	tbl := &table{header: []string{"extLineItemId", "state"}}
+	for _, l := range res.List {
+		tbl.add(l.ExtLineItemID, l.State.String())
+	}
+	return env.print(res.List, tbl)
+}
+
+func closeProject(env *environment, c *samplify.Client) error {
+	res, err := c.CloseProjectWithContext(env.ctx, env.args[0])
+	if err != nil {
+		return err
+	}
+	tbl := &table{header: []string{"extProjectId", "extLineItemId", "state"}}
+	if res.Project != nil {
+		tbl.add(res.Project.ExtProjectID, "", res.Project.State.String())
+		for _, l := range res.Project.LineItems {
+			tbl.add(res.Project.ExtProjectID, l.ExtLineItemID, l.State.String())
+		}
+	}
+	return env.print(res.Project, tbl)
+}
+
+func exportProject(env *environment, c *samplify.Client) error {
+	if len(env.out) == 0 {
+		return errors.New("the file to write the archive to must be given with --out")
+	}
+	f, err := os.Create(env.out)
+	if err != nil {
+		return err
+	}
+	manifest, err := c.ExportProjectWithContext(env.ctx, env.args[0], f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(env.out)
+		return err
+	}
+	tbl := &table{header: []string{"extProjectId", "version", "file", "contents"}}
+	tbl.add(manifest.ExtProjectID, fmt.Sprint(manifest.Version), env.out, strings.Join(manifest.Files, " "))
+	return env.print(manifest, tbl)
+}
+
+func importProject(env *environment, c *samplify.Client) error {
+	var (
+		r    io.ReaderAt
+		size int64
+	)
+	if env.file == "-" {
+		b, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		r, size = bytes.NewReader(b), int64(len(b))
+	} else {
+		f, err := os.Open(env.file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		r, size = f, info.Size()
+	}
+	res, err := c.ImportProjectWithContext(env.ctx, r, size, "")
+	if err != nil {
+		return err
+	}
+	return env.print(res.Project, projectTable(res.Project))
+}
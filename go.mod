@@ -9,4 +9,5 @@ require (
 	github.com/icrowley/fake v0.0.0-20180203215853-4178557ae428 // indirect
 	github.com/leebenson/conform v0.0.0-20190822094432-4c55492f71d7
 	github.com/stretchr/testify v1.4.0 // indirect
+	sigs.k8s.io/yaml v1.2.0
 )
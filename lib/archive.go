@@ -0,0 +1,303 @@
+package samplify
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ArchiveVersion is the version of the archive format written by ExportProject.
+const ArchiveVersion = 1
+
+// Errors returned when reading a project archive
+var (
+	ErrUnsupportedArchiveVersion = errors.New("unsupported project archive version")
+	ErrInvalidArchive            = errors.New("invalid project archive")
+)
+
+// Files of a project archive
+const (
+	ArchiveManifestFile    = "manifest.json"
+	ArchiveProjectFile     = "project.json"
+	ArchiveLineItemsFile   = "lineitems.json"
+	ArchivePermissionsFile = "permissions.json"
+	ArchiveReportFile      = "reports/project.json"
+	ArchiveEventsFile      = "events.json"
+	ArchiveInvoiceFile     = "invoice.pdf"
+)
+
+// ArchiveLineItemReportFile returns the name of the file holding the detailed report of a line item. The ID is path
+// escaped, so that it cannot name a file outside of reports/lineitems.
+func ArchiveLineItemReportFile(extLineItemID string) string {
+	return fmt.Sprintf("reports/lineitems/%s.json", url.PathEscape(extLineItemID))
+}
+
+const eventsPageSize = 1000
+
+// ArchiveManifest describes the content of a project archive.
+type ArchiveManifest struct {
+	Version      int       `json:"version"`
+	ExtProjectID string    `json:"extProjectId"`
+	ExportedAt   time.Time `json:"exportedAt"`
+	Files        []string  `json:"files"`
+}
+
+// ProjectArchive is a full snapshot of a project, as written by ExportProject. Invoice is empty if the project has
+// not been invoiced.
+type ProjectArchive struct {
+	Manifest        *ArchiveManifest
+	Project         *Project
+	LineItems       []*LineItem
+	Permissions     *ProjectPermissions
+	Report          *DetailedProjectReport
+	LineItemReports []*DetailedLineItemReport
+	Events          []*Event
+	Invoice         []byte
+}
+
+// ToCreateCriteria returns the criteria to recreate the archived project, with the quota plans of its line items.
+func (a *ProjectArchive) ToCreateCriteria() *CreateProjectCriteria {
+	project := *a.Project
+	project.LineItems = a.LineItems
+	return project.ToCreateCriteria()
+}
+
+// ExportProjectWithContext writes a versioned zip archive of a project to w. The archive holds the project, its line
+// items with their quota plans, the permissions, the detailed reports, the events and the invoice when there is one.
+func (c *Client) ExportProjectWithContext(ctx context.Context, extProjectID string, w io.Writer) (*ArchiveManifest, error) {
+	err := ValidateNotEmpty(extProjectID)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := c.fetchProjectArchive(ctx, extProjectID)
+	if err != nil {
+		return nil, err
+	}
+	return archive.Manifest, archive.write(w)
+}
+
+// ExportProject writes a versioned zip archive of a project to w.
+func (c *Client) ExportProject(extProjectID string, w io.Writer) (*ArchiveManifest, error) {
+	return c.ExportProjectWithContext(context.Background(), extProjectID, w)
+}
+
+// ImportProjectWithContext creates a project from an archive written by ExportProject. The project keeps its
+// archived id unless extProjectID is given. Permissions, reports, events and invoices are not imported.
+func (c *Client) ImportProjectWithContext(ctx context.Context, r io.ReaderAt, size int64, extProjectID string) (*ProjectResponse, error) {
+	archive, err := ReadProjectArchive(r, size)
+	if err != nil {
+		return nil, err
+	}
+	criteria := archive.ToCreateCriteria()
+	if len(extProjectID) > 0 {
+		criteria.ExtProjectID = extProjectID
+	}
+	return c.CreateProjectWithContext(ctx, criteria)
+}
+
+// ImportProject creates a project from an archive written by ExportProject.
+func (c *Client) ImportProject(r io.ReaderAt, size int64, extProjectID string) (*ProjectResponse, error) {
+	return c.ImportProjectWithContext(context.Background(), r, size, extProjectID)
+}
+
+// ReadProjectArchive reads and validates an archive written by ExportProject.
+func ReadProjectArchive(r io.ReaderAt, size int64) (*ProjectArchive, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	read := func(name string, v interface{}) error {
+		f, ok := files[name]
+		if !ok {
+			return fmt.Errorf("%w: missing %s", ErrInvalidArchive, name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		if b, ok := v.(*[]byte); ok {
+			*b, err = ioutil.ReadAll(rc)
+			return err
+		}
+		if err := json.NewDecoder(rc).Decode(v); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidArchive, name, err)
+		}
+		return nil
+	}
+
+	archive := &ProjectArchive{Manifest: &ArchiveManifest{}}
+	if err := read(ArchiveManifestFile, archive.Manifest); err != nil {
+		return nil, err
+	}
+	if archive.Manifest.Version < 1 || archive.Manifest.Version > ArchiveVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedArchiveVersion, archive.Manifest.Version)
+	}
+	for _, name := range archive.Manifest.Files {
+		if _, ok := files[name]; !ok {
+			return nil, fmt.Errorf("%w: missing %s", ErrInvalidArchive, name)
+		}
+	}
+	if err := read(ArchiveProjectFile, &archive.Project); err != nil {
+		return nil, err
+	}
+	if archive.Project == nil || archive.Project.ExtProjectID != archive.Manifest.ExtProjectID {
+		return nil, fmt.Errorf("%w: %s does not match the manifest", ErrInvalidArchive, ArchiveProjectFile)
+	}
+	if err := read(ArchiveLineItemsFile, &archive.LineItems); err != nil {
+		return nil, err
+	}
+	if err := read(ArchivePermissionsFile, &archive.Permissions); err != nil {
+		return nil, err
+	}
+	if err := read(ArchiveReportFile, &archive.Report); err != nil {
+		return nil, err
+	}
+	if err := read(ArchiveEventsFile, &archive.Events); err != nil {
+		return nil, err
+	}
+	for _, l := range archive.LineItems {
+		report := &DetailedLineItemReport{}
+		if err := read(ArchiveLineItemReportFile(l.ExtLineItemID), report); err != nil {
+			return nil, err
+		}
+		archive.LineItemReports = append(archive.LineItemReports, report)
+	}
+	if _, ok := files[ArchiveInvoiceFile]; ok {
+		if err := read(ArchiveInvoiceFile, &archive.Invoice); err != nil {
+			return nil, err
+		}
+	}
+	return archive, nil
+}
+
+func (c *Client) fetchProjectArchive(ctx context.Context, extProjectID string) (*ProjectArchive, error) {
+	project, err := c.GetProjectByWithContext(ctx, extProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if project.Project == nil {
+		return nil, ErrRequiredFieldEmpty
+	}
+	archive := &ProjectArchive{
+		Manifest: &ArchiveManifest{
+			Version:      ArchiveVersion,
+			ExtProjectID: extProjectID,
+			ExportedAt:   time.Now().UTC(),
+		},
+		Project: project.Project,
+	}
+	for _, l := range project.Project.LineItems {
+		lineItem, err := c.GetLineItemByWithContext(ctx, extProjectID, l.ExtLineItemID)
+		if err != nil {
+			return nil, err
+		}
+		if lineItem.Item == nil {
+			return nil, ErrRequiredFieldEmpty
+		}
+		archive.LineItems = append(archive.LineItems, lineItem.Item)
+		report, err := c.GetDetailedLineItemReportWithContext(ctx, extProjectID, l.ExtLineItemID)
+		if err != nil {
+			return nil, err
+		}
+		archive.LineItemReports = append(archive.LineItemReports, &report.Report)
+	}
+	permissions, err := c.ProjectPermissionsWithContext(ctx, extProjectID)
+	if err != nil {
+		return nil, err
+	}
+	archive.Permissions = permissions.ProjectPermissions
+	report, err := c.GetDetailedProjectReportWithContext(ctx, extProjectID)
+	if err != nil {
+		return nil, err
+	}
+	archive.Report = &report.Report
+	options := &QueryOptions{
+		FilterBy: []*Filter{{Field: QueryFieldExtProjectID, Value: FilterValue{Value: extProjectID}}},
+		Limit:    eventsPageSize,
+	}
+	for {
+		events, err := c.GetEventsWithContext(ctx, options)
+		if err != nil {
+			return nil, err
+		}
+		archive.Events = append(archive.Events, events.List...)
+		if len(events.List) < eventsPageSize {
+			break
+		}
+		options.Offset += eventsPageSize
+	}
+	res, err := c.GetInvoiceWithContext(ctx, extProjectID, nil)
+	if errResp, ok := err.(*ErrorResponse); ok && errResp.HTTPCode == http.StatusNotFound {
+		return archive, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	invoice := &Invoice{}
+	if err := json.Unmarshal(res.Body, invoice); err != nil {
+		return nil, err
+	}
+	archive.Invoice = invoice.File
+	return archive, nil
+}
+
+type archiveFile struct {
+	name string
+	v    interface{}
+}
+
+func (a *ProjectArchive) write(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	add := func(name string, v interface{}) error {
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: a.Manifest.ExportedAt})
+		if err != nil {
+			return err
+		}
+		if b, ok := v.([]byte); ok {
+			_, err = f.Write(b)
+			return err
+		}
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	files := []archiveFile{
+		{ArchiveProjectFile, a.Project},
+		{ArchiveLineItemsFile, a.LineItems},
+		{ArchivePermissionsFile, a.Permissions},
+		{ArchiveReportFile, a.Report},
+		{ArchiveEventsFile, a.Events},
+	}
+	for _, r := range a.LineItemReports {
+		files = append(files, archiveFile{ArchiveLineItemReportFile(r.ExtLineItemID), r})
+	}
+	if len(a.Invoice) > 0 {
+		files = append(files, archiveFile{ArchiveInvoiceFile, a.Invoice})
+	}
+	a.Manifest.Files = make([]string, 0, len(files))
+	for _, f := range files {
+		a.Manifest.Files = append(a.Manifest.Files, f.name)
+	}
+	if err := add(ArchiveManifestFile, a.Manifest); err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := add(f.name, f.v); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
@@ -0,0 +1,170 @@
+package samplify_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"strings"
+	"testing"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+const archiveLineItem = `{"data": {
+	"extLineItemId": "li-w1",
+	"state": "CLOSED",
+	"title": "US Wave 1",
+	"countryISOCode": "US",
+	"languageISOCode": "en",
+	"surveyURL": "www.mysurvey.com/live/survey",
+	"indicativeIncidence": 20,
+	"daysInField": 20,
+	"fieldSchedule": {"startTime": "2020-01-06T09:00:00-05:00", "endTime": "2020-01-26T09:00:00-05:00"},
+	"lengthOfInterview": 10,
+	"targets": [{"count": 100, "type": "COMPLETE"}],
+	"quotaPlan": {"quotaGroups": [{"quotaGroupId": "g1", "name": "gender", "quotaCells": [
+		{"quotaCellId": "1", "quotaNodes": [{"attributeId": "11", "options": ["1"]}], "perc": 50}
+	]}]}
+}}`
+
+// archiveEvents is the number of events of the exported project, more than a page.
+const archiveEvents = 1500
+
+func TestExportImportProject(t *testing.T) {
+	var events []string
+	src := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/projects/src":
+			w.Write([]byte(cloneSourceProject))
+		case "/projects/src/lineItems/li-w1":
+			w.Write([]byte(archiveLineItem))
+		case "/projects/src/lineItems/li-w1/detailedReport":
+			w.Write([]byte(`{"data": {"extLineItemId": "li-w1", "stats": {"completes": 100}}}`))
+		case "/projects/src/detailedReport":
+			w.Write([]byte(`{"data": {"extProjectId": "src"}}`))
+		case "/projects/src/permissions":
+			w.Write([]byte(`{"data": {"extProjectId": "src"}}`))
+		case "/events":
+			events = append(events, r.URL.RawQuery)
+			q := r.URL.Query()
+			if q.Get("extProjectId") != "src" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			offset, _ := strconv.Atoi(q.Get("offset"))
+			limit, _ := strconv.Atoi(q.Get("limit"))
+			var list []string
+			for i := offset; i < archiveEvents && i < offset+limit; i++ {
+				list = append(list, fmt.Sprintf(`{"eventId": %d, "extProjectId": "src"}`, i))
+			}
+			fmt.Fprintf(w, `{"data": [%s]}`, strings.Join(list, ","))
+		case "/projects/src/invoices":
+			w.Write([]byte(`{"data": "JVBERi0="}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer src.Close()
+	srcClient := samplify.NewClient("", "", "", &samplify.ClientOptions{APIBaseURL: src.URL, AuthURL: src.URL})
+	srcClient.Auth = getAuth()
+
+	buf := &bytes.Buffer{}
+	manifest, err := srcClient.ExportProject("src", buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Version != samplify.ArchiveVersion || len(manifest.Files) != 7 {
+		t.Errorf("unexpected manifest %+v", manifest)
+	}
+	if len(events) != 2 || events[1] != "extProjectId=src&offset=1000&limit=1000" {
+		t.Errorf("got event requests %v, want 2 with extProjectId=src&offset=1000&limit=1000 last", events)
+	}
+
+	archive, err := samplify.ReadProjectArchive(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archive.LineItems) != 1 || archive.LineItems[0].QuotaPlan == nil || len(archive.LineItemReports) != 1 ||
+		len(archive.Events) != archiveEvents || string(archive.Invoice) != "%PDF-" {
+		t.Errorf("unexpected archive %+v", archive)
+	}
+
+	var created *samplify.CreateProjectCriteria
+	dst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		created = &samplify.CreateProjectCriteria{}
+		json.Unmarshal(b, created)
+		w.Write([]byte(`{"data": {"extProjectId": "restored"}}`))
+	}))
+	defer dst.Close()
+	dstClient := samplify.NewClient("", "", "", &samplify.ClientOptions{APIBaseURL: dst.URL, AuthURL: dst.URL})
+	dstClient.Auth = getAuth()
+
+	if _, err := dstClient.ImportProject(bytes.NewReader(buf.Bytes()), int64(buf.Len()), "restored"); err != nil {
+		t.Fatal(err)
+	}
+	if created == nil || created.ExtProjectID != "restored" || len(created.LineItems) != 1 {
+		t.Fatalf("unexpected project criteria %+v", created)
+	}
+	qp := created.LineItems[0].QuotaPlan
+	if qp == nil || len(qp.QuotaGroups) != 1 || qp.QuotaGroups[0].QuotaGroupID != nil {
+		t.Errorf("unexpected quota plan %+v", qp)
+	}
+	if l := created.LineItems[0]; l.FieldSchedule != nil || l.DaysInField != 20 {
+		t.Errorf("expected the past schedule to give way to 20 days in field, got %v and %d", l.FieldSchedule, l.DaysInField)
+	}
+}
+
+func TestReadProjectArchive(t *testing.T) {
+	tables := []struct {
+		name     string
+		manifest string
+		err      error
+	}{
+		{"Case 1: newer version", `{"version": 2, "extProjectId": "src"}`, samplify.ErrUnsupportedArchiveVersion},
+		{"Case 2: missing version", `{"extProjectId": "src"}`, samplify.ErrUnsupportedArchiveVersion},
+		{"Case 3: missing file", `{"version": 1, "extProjectId": "src", "files": ["project.json"]}`, samplify.ErrInvalidArchive},
+	}
+
+	for _, table := range tables {
+		buf := &bytes.Buffer{}
+		zw := zip.NewWriter(buf)
+		f, _ := zw.Create(samplify.ArchiveManifestFile)
+		f.Write([]byte(table.manifest))
+		zw.Close()
+
+		_, err := samplify.ReadProjectArchive(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if !errors.Is(err, table.err) {
+			t.Errorf("%s: got error %v, want %v", table.name, err, table.err)
+		}
+	}
+}
+
+func TestArchiveLineItemReportFile(t *testing.T) {
+	tables := []struct {
+		id       string
+		expected string
+	}{
+		{"lineItem001", "reports/lineitems/lineItem001.json"},
+		{"../../etc/passwd", "reports/lineitems/..%2F..%2Fetc%2Fpasswd.json"},
+		{`..\evil`, "reports/lineitems/..%5Cevil.json"},
+		{"..", "reports/lineitems/...json"},
+	}
+
+	for _, table := range tables {
+		name := samplify.ArchiveLineItemReportFile(table.id)
+		if name != table.expected {
+			t.Errorf("%s: got %s, want %s", table.id, name, table.expected)
+		}
+		if path.Dir(name) != "reports/lineitems" {
+			t.Errorf("%s: %s is outside of reports/lineitems", table.id, name)
+		}
+	}
+}
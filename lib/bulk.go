@@ -0,0 +1,245 @@
+package samplify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DefaultBulkConcurrency is the number of requests run at the same time by the bulk operations.
+const DefaultBulkConcurrency = 4
+
+const bulkProjectsPageSize = 100
+
+// BulkStatus is the outcome of a bulk operation for one line item.
+type BulkStatus string
+
+// BulkStatus values
+const (
+	BulkStatusChanged     BulkStatus = "CHANGED"
+	BulkStatusSkipped     BulkStatus = "SKIPPED"
+	BulkStatusNotEligible BulkStatus = "NOT_ELIGIBLE"
+	BulkStatusFailed      BulkStatus = "FAILED"
+)
+
+// BulkOptions configures the bulk operations.
+type BulkOptions struct {
+	// Concurrency is the number of requests run at the same time.
+	Concurrency int
+	// Filter selects the line items to change. All line items are changed if nil.
+	Filter func(extProjectID string, l *LineItem) bool
+}
+
+// BulkItemResult is the result of a bulk operation for one line item. ExtLineItemID is empty when the project itself
+// could not be read.
+type BulkItemResult struct {
+	ExtProjectID  string
+	ExtLineItemID string
+	Status        BulkStatus
+	From          State
+	To            State
+	Err           error
+}
+
+// BulkReport is the result of a bulk operation, with one entry per line item.
+type BulkReport struct {
+	Action Action
+	Items  []*BulkItemResult
+}
+
+// Count returns the number of line items with the given status.
+func (r *BulkReport) Count(status BulkStatus) int {
+	n := 0
+	for _, i := range r.Items {
+		if i.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Failed returns the results of the line items that could not be changed because of an error.
+func (r *BulkReport) Failed() []*BulkItemResult {
+	var res []*BulkItemResult
+	for _, i := range r.Items {
+		if i.Status == BulkStatusFailed {
+			res = append(res, i)
+		}
+	}
+	return res
+}
+
+// Err returns an error listing the failed line items, or nil if there is none.
+func (r *BulkReport) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(failed))
+	for _, i := range failed {
+		if len(i.ExtLineItemID) == 0 {
+			msgs = append(msgs, fmt.Sprintf("project %s: %v", i.ExtProjectID, i.Err))
+			continue
+		}
+		msgs = append(msgs, fmt.Sprintf("project %s, line item %s: %v", i.ExtProjectID, i.ExtLineItemID, i.Err))
+	}
+	return fmt.Errorf("%d of %d line items failed to %s:\n%s", len(failed), len(r.Items), r.Action, strings.Join(msgs, "\n"))
+}
+
+// BulkUpdateLineItemStateWithContext applies an action to every line item of the given projects, running up to
+// BulkOptions.Concurrency requests at the same time. Line items already in the resulting state are skipped, and
+// those whose state does not allow the action are not eligible. The changes go through UpdateLineItemState, and so
+// check the current state unless ClientOptions.SkipTransitionCheck is set. Failures are reported per line item in
+// the report; the returned error is only set if the action is invalid.
+func (c *Client) BulkUpdateLineItemStateWithContext(ctx context.Context, extProjectIDs []string, action Action, opts *BulkOptions) (*BulkReport, error) {
+	err := ValidateAction(action)
+	if err != nil {
+		return nil, err
+	}
+	o := BulkOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = DefaultBulkConcurrency
+	}
+
+	projects := make([]*Project, len(extProjectIDs))
+	fetchErrs := make([]error, len(extProjectIDs))
+	runConcurrently(ctx, len(extProjectIDs), o.Concurrency, func(i int) {
+		res, err := c.GetProjectByWithContext(ctx, extProjectIDs[i])
+		if err == nil && res.Project == nil {
+			err = ErrRequiredFieldEmpty
+		}
+		if err != nil {
+			fetchErrs[i] = err
+			return
+		}
+		projects[i] = res.Project
+	})
+
+	report := &BulkReport{Action: action}
+	var pending []*BulkItemResult
+	for i, p := range projects {
+		if p == nil {
+			err := fetchErrs[i]
+			if err == nil {
+				err = ctx.Err()
+			}
+			report.Items = append(report.Items, &BulkItemResult{ExtProjectID: extProjectIDs[i], Status: BulkStatusFailed, Err: err})
+			continue
+		}
+		for _, l := range p.LineItems {
+			if o.Filter != nil && !o.Filter(p.ExtProjectID, l) {
+				continue
+			}
+			item := &BulkItemResult{ExtProjectID: p.ExtProjectID, ExtLineItemID: l.ExtLineItemID, From: l.State, To: l.State}
+			report.Items = append(report.Items, item)
+			switch {
+			case isInActionState(l.State, action):
+				item.Status = BulkStatusSkipped
+			case !CanApply(l.State, action):
+				item.Status = BulkStatusNotEligible
+				item.Err = &TransitionError{ExtLineItemID: l.ExtLineItemID, State: l.State, Action: action}
+			default:
+				pending = append(pending, item)
+			}
+		}
+	}
+
+	runConcurrently(ctx, len(pending), o.Concurrency, func(i int) {
+		item := pending[i]
+		res, err := c.UpdateLineItemStateWithContext(ctx, item.ExtProjectID, item.ExtLineItemID, action)
+		if err != nil {
+			item.Status, item.Err = BulkStatusFailed, err
+			return
+		}
+		item.Status = BulkStatusChanged
+		if res.LineItem != nil {
+			item.To = res.LineItem.State
+		}
+	})
+	for _, item := range pending {
+		if len(item.Status) == 0 {
+			item.Status, item.Err = BulkStatusFailed, ctx.Err()
+		}
+	}
+	return report, nil
+}
+
+// BulkUpdateLineItemState applies an action to every line item of the given projects.
+func (c *Client) BulkUpdateLineItemState(extProjectIDs []string, action Action, opts *BulkOptions) (*BulkReport, error) {
+	return c.BulkUpdateLineItemStateWithContext(context.Background(), extProjectIDs, action, opts)
+}
+
+// BulkUpdateLineItemStateByQueryWithContext applies an action to every line item of the projects matching the
+// query. All the pages of projects are read, starting from the query offset.
+func (c *Client) BulkUpdateLineItemStateByQueryWithContext(ctx context.Context, query *QueryOptions, action Action, opts *BulkOptions) (*BulkReport, error) {
+	err := ValidateAction(action)
+	if err != nil {
+		return nil, err
+	}
+	q := QueryOptions{}
+	if query != nil {
+		q = *query
+	}
+	if q.Limit == 0 {
+		q.Limit = bulkProjectsPageSize
+	}
+	var ids []string
+	for {
+		res, err := c.GetAllProjectsWithContext(ctx, &q)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range res.Projects {
+			ids = append(ids, p.ExtProjectID)
+		}
+		if uint(len(res.Projects)) < q.Limit {
+			break
+		}
+		q.Offset += q.Limit
+	}
+	return c.BulkUpdateLineItemStateWithContext(ctx, ids, action, opts)
+}
+
+// BulkUpdateLineItemStateByQuery applies an action to every line item of the projects matching the query.
+func (c *Client) BulkUpdateLineItemStateByQuery(query *QueryOptions, action Action, opts *BulkOptions) (*BulkReport, error) {
+	return c.BulkUpdateLineItemStateByQueryWithContext(context.Background(), query, action, opts)
+}
+
+// isInActionState tells whether a line item is already in the state an action leads to.
+func isInActionState(state State, action Action) bool {
+	switch action {
+	case ActionLaunched:
+		return state == StateLaunched
+	case ActionPaused:
+		return state == StatePaused || state == StateAwaitingApprovalPaused || state == StateRejectedPaused
+	case ActionClosed:
+		return state == StateClosed || state == StateCancelled || state == StateInvoiced
+	}
+	return false
+}
+
+// runConcurrently calls f for every index from 0 to n-1, with at most limit calls running at the same time. It stops
+// starting new calls once the context is done.
+func runConcurrently(ctx context.Context, n, limit int, f func(i int)) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, limit)
+	for i := 0; i < n; i++ {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			f(i)
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,173 @@
+package samplify_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+func TestBulkUpdateLineItemState(t *testing.T) {
+	var running, maxRunning int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/projects":
+			w.Write([]byte(`{"data": [{"extProjectId": "p1"}, {"extProjectId": "p2"}, {"extProjectId": "p3"}]}`))
+		case "/projects/p1":
+			w.Write([]byte(`{"data": {"extProjectId": "p1", "lineItems": [
+				{"extLineItemId": "li1", "state": "LAUNCHED"},
+				{"extLineItemId": "li2", "state": "PAUSED"},
+				{"extLineItemId": "li3", "state": "CLOSED"},
+				{"extLineItemId": "li5", "state": "LAUNCHED"}
+			]}}`))
+		case "/projects/p2":
+			w.Write([]byte(`{"data": {"extProjectId": "p2", "lineItems": [{"extLineItemId": "li4", "state": "LAUNCHED"}]}}`))
+		case "/projects/p1/lineItems/li1", "/projects/p1/lineItems/li5":
+			fmt.Fprintf(w, `{"data": {"extLineItemId": %q, "state": "LAUNCHED"}}`, path.Base(r.URL.Path))
+		case "/projects/p1/lineItems/li1/pause", "/projects/p1/lineItems/li5/pause":
+			n := atomic.AddInt32(&running, 1)
+			if n > atomic.LoadInt32(&maxRunning) {
+				atomic.StoreInt32(&maxRunning, n)
+			}
+			defer atomic.AddInt32(&running, -1)
+			w.Write([]byte(`{"data": {"state": "PAUSED"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	client := samplify.NewClient("", "", "", &samplify.ClientOptions{APIBaseURL: ts.URL, AuthURL: ts.URL})
+	client.Auth = getAuth()
+
+	expected := "p1/li1:CHANGED:PAUSED p1/li2:SKIPPED:PAUSED p1/li3:NOT_ELIGIBLE:CLOSED p1/li5:CHANGED:PAUSED " +
+		"p2/li4:FAILED:LAUNCHED p3/:FAILED:"
+	summary := func(r *samplify.BulkReport) string {
+		var s []string
+		for _, i := range r.Items {
+			s = append(s, fmt.Sprintf("%s/%s:%s:%s", i.ExtProjectID, i.ExtLineItemID, i.Status, i.To))
+		}
+		return fmt.Sprint(s)
+	}
+
+	report, err := client.BulkUpdateLineItemState([]string{"p1", "p2", "p3"}, samplify.ActionPaused, &samplify.BulkOptions{Concurrency: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary(report) != "["+expected+"]" {
+		t.Errorf("got report\n%s\nwant\n[%s]", summary(report), expected)
+	}
+	if maxRunning != 1 || len(report.Failed()) != 2 || report.Count(samplify.BulkStatusChanged) != 2 || report.Err() == nil {
+		t.Errorf("unexpected report %s, %d requests at the same time", summary(report), maxRunning)
+	}
+
+	report, err = client.BulkUpdateLineItemStateByQuery(nil, samplify.ActionPaused, &samplify.BulkOptions{
+		Filter: func(extProjectID string, l *samplify.LineItem) bool { return l.ExtLineItemID != "li5" },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Count(samplify.BulkStatusChanged) != 1 || len(report.Items) != 5 {
+		t.Errorf("unexpected report %s", summary(report))
+	}
+
+	if _, err := client.BulkUpdateLineItemState([]string{"p1"}, "stop", nil); err != samplify.ErrInvalidFieldValue {
+		t.Errorf("got error %v, want ErrInvalidFieldValue", err)
+	}
+}
+
+func TestBulkUpdateLineItemStateExpiredToken(t *testing.T) {
+	var refreshes, unauthorized int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token/refresh":
+			atomic.AddInt32(&refreshes, 1)
+			w.Write([]byte(`{"accessToken": "fresh", "expiresIn": 1800, "refreshToken": "refresh", "refreshExpiresIn": 3600}`))
+		case r.Header.Get("Authorization") != "Bearer fresh":
+			atomic.AddInt32(&unauthorized, 1)
+			w.WriteHeader(http.StatusUnauthorized)
+		case strings.HasSuffix(r.URL.Path, "/pause"):
+			w.Write([]byte(`{"data": {"state": "PAUSED"}}`))
+		case strings.Contains(r.URL.Path, "/lineItems/"):
+			fmt.Fprintf(w, `{"data": {"extLineItemId": %q, "state": "LAUNCHED"}}`, path.Base(r.URL.Path))
+		default:
+			var items []string
+			for i := 0; i < 10; i++ {
+				items = append(items, fmt.Sprintf(`{"extLineItemId": "li%d", "state": "LAUNCHED"}`, i))
+			}
+			fmt.Fprintf(w, `{"data": {"extProjectId": "%s", "lineItems": [%s]}}`, path.Base(r.URL.Path), strings.Join(items, ","))
+		}
+	}))
+	defer ts.Close()
+	acquired := time.Now().Add(-time.Hour)
+	client := samplify.NewClient("", "", "", &samplify.ClientOptions{APIBaseURL: ts.URL, AuthURL: ts.URL})
+	client.Auth = samplify.TokenResponse{AccessToken: "expired", ExpiresIn: 1800, RefreshToken: "refresh",
+		RefreshExpiresIn: 7200, Acquired: &acquired}
+
+	report, err := client.BulkUpdateLineItemState([]string{"p1", "p2", "p3", "p4"}, samplify.ActionPaused,
+		&samplify.BulkOptions{Concurrency: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Count(samplify.BulkStatusChanged) != 40 || report.Err() != nil {
+		t.Errorf("got %d changed line items and error %v, want 40", report.Count(samplify.BulkStatusChanged), report.Err())
+	}
+	if refreshes != 1 || unauthorized != 0 {
+		t.Errorf("got %d token refreshes and %d unauthorized requests, want 1 and 0", refreshes, unauthorized)
+	}
+}
+
+func TestBulkUpdateLineItemStateByQueryPages(t *testing.T) {
+	var queries []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/pause"):
+			w.Write([]byte(`{"data": {"state": "PAUSED"}}`))
+			return
+		case strings.Contains(r.URL.Path, "/lineItems/"):
+			w.Write([]byte(`{"data": {"extLineItemId": "li", "state": "LAUNCHED"}}`))
+			return
+		case r.URL.Path != "/projects":
+			fmt.Fprintf(w, `{"data": {"extProjectId": %q, "lineItems": [{"extLineItemId": "li", "state": "LAUNCHED"}]}}`,
+				path.Base(r.URL.Path))
+			return
+		}
+		queries = append(queries, r.URL.RawQuery)
+		if r.URL.Query().Get("state") != "LAUNCHED" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		var projects []string
+		for i := offset; i < 3 && i < offset+limit; i++ {
+			projects = append(projects, fmt.Sprintf(`{"extProjectId": "p%d"}`, i))
+		}
+		fmt.Fprintf(w, `{"data": [%s]}`, strings.Join(projects, ","))
+	}))
+	defer ts.Close()
+	client := samplify.NewClient("", "", "", &samplify.ClientOptions{APIBaseURL: ts.URL, AuthURL: ts.URL})
+	client.Auth = getAuth()
+
+	query := &samplify.QueryOptions{
+		FilterBy: []*samplify.Filter{{Field: samplify.QueryFieldState, Value: samplify.FilterValue{Value: samplify.StateLaunched}}},
+		Limit:    2,
+	}
+	report, err := client.BulkUpdateLineItemStateByQuery(query, samplify.ActionPaused, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "[state=LAUNCHED&limit=2 state=LAUNCHED&offset=2&limit=2]"
+	if fmt.Sprint(queries) != expected {
+		t.Errorf("got queries %v, want %s", queries, expected)
+	}
+	if report.Count(samplify.BulkStatusChanged) != 3 {
+		t.Errorf("got %d changed line items, want 3", report.Count(samplify.BulkStatusChanged))
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
+	"sync"
 	"time"
 )
 
@@ -53,6 +55,9 @@ type ClientOptions struct {
 	GatewayURL string `conform:"trim"`
 	Timeout    *int
 	HTTPClient httpClient
+	// SkipTransitionCheck disables the check of the current state made by UpdateLineItemState and
+	// SetQuotaCellStatus before calling the API.
+	SkipTransitionCheck bool
 }
 
 // Client is used to make API requests to the Samplify API.
@@ -61,6 +66,8 @@ type Client struct {
 	Auth        TokenResponse
 	Options     *ClientOptions
 	HTTPClient  httpClient
+	// authMu serialises the token renewals of concurrent requests.
+	authMu sync.Mutex
 }
 
 // GetInvoicesSummaryWithContext ...
@@ -74,12 +81,31 @@ func (c *Client) GetInvoicesSummary(options *QueryOptions) (*APIResponse, error)
 	return c.GetInvoicesSummaryWithContext(context.Background(), options)
 }
 
+// GetInvoiceSummariesWithContext returns the same data as GetInvoicesSummaryWithContext decoded into typed summaries.
+func (c *Client) GetInvoiceSummariesWithContext(ctx context.Context, options *QueryOptions) (*GetInvoiceSummariesResponse, error) {
+	res := &GetInvoiceSummariesResponse{}
+	path := fmt.Sprintf("/projects/invoices/summary%s", query2String(options))
+	err := c.requestAndParseResponse(ctx, "GET", path, nil, res)
+	return res, err
+}
+
+// GetInvoiceSummaries returns the same data as GetInvoicesSummary decoded into typed summaries.
+func (c *Client) GetInvoiceSummaries(options *QueryOptions) (*GetInvoiceSummariesResponse, error) {
+	return c.GetInvoiceSummariesWithContext(context.Background(), options)
+}
+
 // CreateProjectWithContext ...
 func (c *Client) CreateProjectWithContext(ctx context.Context, project *CreateProjectCriteria) (*ProjectResponse, error) {
 	err := Validate(project)
 	if err != nil {
 		return nil, err
 	}
+	for _, l := range project.LineItems {
+		err = ValidateSchedule(&l.DaysInField, l.FieldSchedule)
+		if err != nil {
+			return nil, fmt.Errorf("line item %s: %w", l.ExtLineItemID, err)
+		}
+	}
 	res := &ProjectResponse{}
 	err = c.requestAndParseResponse(ctx, "POST", "/projects", project, res)
 	return res, err
@@ -96,12 +122,43 @@ func (c *Client) UpdateProjectWithContext(ctx context.Context, project *UpdatePr
 	if err != nil {
 		return nil, err
 	}
+	if project.LineItems != nil {
+		var current map[string]time.Time
+		for _, l := range *project.LineItems {
+			if l.FieldSchedule.started() && current == nil {
+				current, err = c.currentStarts(ctx, project.ExtProjectID)
+				if err != nil {
+					return nil, err
+				}
+			}
+			err = validateScheduleUpdate(l.DaysInField, l.FieldSchedule, current[l.ExtLineItemID])
+			if err != nil {
+				return nil, fmt.Errorf("line item %s: %w", l.ExtLineItemID, err)
+			}
+		}
+	}
 	res := &ProjectResponse{}
 	path := fmt.Sprintf("/projects/%s", project.ExtProjectID)
 	err = c.requestAndParseResponse(ctx, "POST", path, project, res)
 	return res, err
 }
 
+// currentStarts returns the start of the field schedule of every line item of the project, to let updates keep a
+// start that has passed.
+func (c *Client) currentStarts(ctx context.Context, extProjectID string) (map[string]time.Time, error) {
+	res, err := c.GetProjectByWithContext(ctx, extProjectID)
+	if err != nil {
+		return nil, err
+	}
+	starts := make(map[string]time.Time)
+	if res.Project != nil {
+		for _, l := range res.Project.LineItems {
+			starts[l.ExtLineItemID] = l.FieldSchedule.startOf()
+		}
+	}
+	return starts, nil
+}
+
 // UpdateProject ...
 func (c *Client) UpdateProject(project *UpdateProjectCriteria) (*ProjectResponse, error) {
 	return c.UpdateProjectWithContext(context.Background(), project)
@@ -230,7 +287,17 @@ func (c *Client) UpdateLineItemWithContext(ctx context.Context, extProjectID, ex
 	if err != nil {
 		return nil, err
 	}
-	err = ValidateSchedule(lineItem.DaysInField, lineItem.FieldSchedule)
+	var currentStart time.Time
+	if lineItem.FieldSchedule.started() {
+		current, err := c.GetLineItemByWithContext(ctx, extProjectID, extLineItemID)
+		if err != nil {
+			return nil, err
+		}
+		if current.Item != nil {
+			currentStart = current.Item.FieldSchedule.startOf()
+		}
+	}
+	err = validateScheduleUpdate(lineItem.DaysInField, lineItem.FieldSchedule, currentStart)
 	if err != nil {
 		return nil, err
 	}
@@ -248,6 +315,8 @@ func (c *Client) UpdateLineItem(extProjectID, extLineItemID string,
 }
 
 // UpdateLineItemStateWithContext ... Changes the state of the line item based on provided action.
+// Unless ClientOptions.SkipTransitionCheck is set, a *TransitionError is returned without calling the API if the
+// action is not allowed in the current state of the line item.
 func (c *Client) UpdateLineItemStateWithContext(ctx context.Context, extProjectID, extLineItemID string, action Action) (
 	*UpdateLineItemStateResponse, error) {
 	err := ValidateNotEmpty(extProjectID, extLineItemID)
@@ -258,9 +327,19 @@ func (c *Client) UpdateLineItemStateWithContext(ctx context.Context, extProjectI
 	if err != nil {
 		return nil, err
 	}
+	if !c.Options.SkipTransitionCheck {
+		err = c.checkLineItemTransition(ctx, extProjectID, extLineItemID, action)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.updateLineItemState(ctx, extProjectID, extLineItemID, action)
+}
+
+func (c *Client) updateLineItemState(ctx context.Context, extProjectID, extLineItemID string, action Action) (*UpdateLineItemStateResponse, error) {
 	res := &UpdateLineItemStateResponse{}
 	path := fmt.Sprintf("/projects/%s/lineItems/%s/%s", extProjectID, extLineItemID, action)
-	err = c.requestAndParseResponse(ctx, "POST", path, nil, res)
+	err := c.requestAndParseResponse(ctx, "POST", path, nil, res)
 	return res, err
 }
 
@@ -301,6 +380,8 @@ func (c *Client) CloseLineItem(pid, lid string) (*UpdateLineItemStateResponse, e
 }
 
 // SetQuotaCellStatusWithContext ... Changes the state of the line item based on provided action.
+// Unless ClientOptions.SkipTransitionCheck is set, a *TransitionError is returned without calling the API if the
+// quota cell already has the requested status.
 func (c *Client) SetQuotaCellStatusWithContext(ctx context.Context, extProjectID, extLineItemID string, quotaCellID string, action Action) (
 	*QuotaCellResponse, error) {
 	err := ValidateNotEmpty(extProjectID, extLineItemID, quotaCellID)
@@ -311,6 +392,12 @@ func (c *Client) SetQuotaCellStatusWithContext(ctx context.Context, extProjectID
 	if err != nil {
 		return nil, err
 	}
+	if !c.Options.SkipTransitionCheck {
+		err = c.checkQuotaCellTransition(ctx, extProjectID, extLineItemID, quotaCellID, action)
+		if err != nil {
+			return nil, err
+		}
+	}
 	res := &QuotaCellResponse{}
 	path := fmt.Sprintf("/projects/%s/lineItems/%s/quotaCells/%s/%s", extProjectID, extLineItemID, quotaCellID, action)
 	err = c.requestAndParseResponse(ctx, "POST", path, nil, res)
@@ -361,6 +448,7 @@ func (c *Client) GetLineItemBy(extProjectID, extLineItemID string) (*LineItemRes
 // seconds to execute. Check the `GetFeasibilityResponse.Feasibility.Status` field value to see if it is
 // FeasibilityStatusReady ("READY") or FeasibilityStatusProcessing ("PROCESSING")
 // If GetFeasibilityResponse.Feasibility.Status == FeasibilityStatusProcessing, call this function again in 2 mins.
+// WaitForFeasibility does the polling.
 func (c *Client) GetFeasibilityWithContext(ctx context.Context, extProjectID string, options *QueryOptions) (*GetFeasibilityResponse, error) {
 	err := ValidateNotEmpty(extProjectID)
 	if err != nil {
@@ -377,6 +465,7 @@ func (c *Client) GetFeasibilityWithContext(ctx context.Context, extProjectID str
 // seconds to execute. Check the `GetFeasibilityResponse.Feasibility.Status` field value to see if it is
 // FeasibilityStatusReady ("READY") or FeasibilityStatusProcessing ("PROCESSING")
 // If GetFeasibilityResponse.Feasibility.Status == FeasibilityStatusProcessing, call this function again in 2 mins.
+// WaitForFeasibility does the polling.
 func (c *Client) GetFeasibility(extProjectID string, options *QueryOptions) (*GetFeasibilityResponse, error) {
 	return c.GetFeasibilityWithContext(context.Background(), extProjectID, options)
 }
@@ -393,17 +482,52 @@ func (c *Client) GetInvoice(extProjectID string, options *QueryOptions) (*APIRes
 }
 
 // UploadReconcileWithContext ...  Upload the Request correction file
+//
+// Deprecated: use UploadReconcileFromReaderWithContext, which accepts any io.Reader and returns a typed result.
 func (c *Client) UploadReconcileWithContext(ctx context.Context, extProjectID string, file multipart.File, fileName string, message string, options *QueryOptions) (*APIResponse, error) {
-	path := fmt.Sprintf("/projects/%s/reconcile", extProjectID)
-	res, err := c.sendFormData(ctx, c.Options.APIBaseURL, "POST", path, c.Auth.AccessToken, file, fileName, message)
-	return res, err
+	err := ValidateNotEmpty(extProjectID)
+	if err != nil {
+		return nil, err
+	}
+	return c.uploadReconcile(ctx, extProjectID, file, fileName, message, options)
 }
 
 // UploadReconcile ...  Upload the Request correction file
+//
+// Deprecated: use UploadReconcileFromReader, which accepts any io.Reader and returns a typed result.
 func (c *Client) UploadReconcile(extProjectID string, file multipart.File, fileName string, message string, options *QueryOptions) (*APIResponse, error) {
 	return c.UploadReconcileWithContext(context.Background(), extProjectID, file, fileName, message, options)
 }
 
+// UploadReconcileFromReaderWithContext uploads the Request correction file read from r. The file is streamed to the
+// API rather than buffered in memory. If r also implements io.Seeker the upload is retried once after re-authenticating
+// when the access token is rejected.
+func (c *Client) UploadReconcileFromReaderWithContext(ctx context.Context, extProjectID string, r io.Reader, fileName string, message string, options *QueryOptions) (*ReconcileResponse, error) {
+	err := ValidateNotEmpty(extProjectID, fileName)
+	if err != nil {
+		return nil, err
+	}
+	res := &ReconcileResponse{}
+	ar, err := c.uploadReconcile(ctx, extProjectID, r, fileName, message, options)
+	if ar != nil && len(ar.Body) > 0 {
+		if uerr := json.Unmarshal(ar.Body, res); uerr != nil && err == nil {
+			err = uerr
+		}
+	}
+	return res, err
+}
+
+// UploadReconcileFromReader uploads the Request correction file read from r.
+func (c *Client) UploadReconcileFromReader(extProjectID string, r io.Reader, fileName string, message string, options *QueryOptions) (*ReconcileResponse, error) {
+	return c.UploadReconcileFromReaderWithContext(context.Background(), extProjectID, r, fileName, message, options)
+}
+
+// uploadReconcile sends the Request correction file read from r, with the query options.
+func (c *Client) uploadReconcile(ctx context.Context, extProjectID string, r io.Reader, fileName string, message string, options *QueryOptions) (*APIResponse, error) {
+	path := fmt.Sprintf("/projects/%s/reconcile%s", extProjectID, query2String(options))
+	return c.requestFormData(ctx, "POST", c.Options.APIBaseURL, path, r, fileName, message)
+}
+
 // GetCountriesWithContext ... Get the list of supported countries and languages in each country.
 func (c *Client) GetCountriesWithContext(ctx context.Context, options *QueryOptions) (*GetCountriesResponse, error) {
 	res := &GetCountriesResponse{}
@@ -798,18 +922,47 @@ func (c *Client) requestAndParseResponse(ctx context.Context, method, url string
 }
 
 func (c *Client) request(ctx context.Context, method, host, url string, body interface{}) (*APIResponse, error) {
-	err := c.validateTokens(ctx)
+	token, err := c.validateTokens(ctx)
 	if err != nil {
 		return nil, err
 	}
-	ar, err := c.sendRequest(ctx, host, method, url, c.Auth.AccessToken, body)
+	ar, err := c.sendRequest(ctx, host, method, url, token, body)
 	errResp, ok := err.(*ErrorResponse)
 	if ok && errResp.HTTPCode == http.StatusUnauthorized {
-		err := c.requestAndParseToken(ctx)
+		token, err := c.renewToken(ctx, token)
 		if err != nil {
 			return nil, err
 		}
-		return c.sendRequest(ctx, host, method, url, c.Auth.AccessToken, body)
+		return c.sendRequest(ctx, host, method, url, token, body)
+	}
+	return ar, err
+}
+
+func (c *Client) requestFormData(ctx context.Context, method, host, path string, file io.Reader, fileName, message string) (*APIResponse, error) {
+	token, err := c.validateTokens(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Remember where the file started so that it can be replayed after re-authenticating.
+	seeker, canRewind := file.(io.Seeker)
+	var start int64
+	if canRewind {
+		start, err = seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			canRewind = false
+		}
+	}
+	ar, err := c.sendFormData(ctx, host, method, path, token, file, fileName, message)
+	errResp, ok := err.(*ErrorResponse)
+	if ok && errResp.HTTPCode == http.StatusUnauthorized && canRewind {
+		token, err := c.renewToken(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return c.sendFormData(ctx, host, method, path, token, file, fileName, message)
 	}
 	return ar, err
 }
@@ -829,18 +982,33 @@ func (c *Client) requestAndParseToken(ctx context.Context) error {
 	return nil
 }
 
-// ValidateTokens ...
-func (c *Client) validateTokens(ctx context.Context) error {
+// ValidateTokens renews the access token if it expired, and returns it.
+func (c *Client) validateTokens(ctx context.Context) (string, error) {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
 	if c.Auth.AccessTokenExpired() {
 		err := c.RefreshTokenWithContext(ctx)
 		if err != nil {
 			err := c.requestAndParseToken(ctx)
 			if err != nil {
-				return err
+				return "", err
 			}
 		}
 	}
-	return nil
+	return c.Auth.AccessToken, nil
+}
+
+// renewToken requests a new access token after rejected was refused, unless a concurrent request already did.
+func (c *Client) renewToken(ctx context.Context, rejected string) (string, error) {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+	if c.Auth.AccessToken == rejected {
+		err := c.requestAndParseToken(ctx)
+		if err != nil {
+			return "", err
+		}
+	}
+	return c.Auth.AccessToken, nil
 }
 
 // NewClient returns an API client.
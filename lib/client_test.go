@@ -1,8 +1,10 @@
 package samplify_test
 
 import (
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -71,7 +73,7 @@ func TestClientFunctions(t *testing.T) {
 		urls = append(urls, r.URL.String())
 	}))
 
-	client := samplify.NewClient("", "", "", nil)
+	client := samplify.NewClient("", "", "", &samplify.ClientOptions{SkipTransitionCheck: true})
 	client.Options.APIBaseURL = ts.URL
 	client.Options.AuthURL = ts.URL
 	client.Auth = getAuth()
@@ -120,7 +122,7 @@ func TestQueryString(t *testing.T) {
 		query       *samplify.QueryOptions
 	}{
 		{
-			expectedURL: "/projects?title=Samplify+Client+Test&amp;state=PROVISIONED",
+			expectedURL: "/projects?title=Samplify+Client+Test&state=PROVISIONED",
 			query:       getQueryOptionsOne(),
 		},
 		{
@@ -128,7 +130,7 @@ func TestQueryString(t *testing.T) {
 			query:       getQueryOptionsTwo(),
 		},
 		{
-			expectedURL: "/projects?title=Samplify+Client+Test&amp;state=PROVISIONED&amp;sort=createdAt:asc,extProjectId:desc",
+			expectedURL: "/projects?title=Samplify+Client+Test&state=PROVISIONED&sort=createdAt:asc,extProjectId:desc",
 			query:       getQueryOptionsThree(),
 		},
 		{
@@ -136,7 +138,7 @@ func TestQueryString(t *testing.T) {
 			query:       getQueryOptionsFour(),
 		},
 		{
-			expectedURL: "/projects?startDate=2019-06-12&amp;endDate=2019-06-19&amp;extProjectId=test-project-id",
+			expectedURL: "/projects?startDate=2019-06-12&endDate=2019-06-19&extProjectId=test-project-id",
 			query:       getQueryOptionsInvoicesSummary(),
 		},
 	}
@@ -309,3 +311,75 @@ func getBuyProjectCriteria() []*samplify.BuyProjectCriteria {
 		},
 	}
 }
+
+func TestUploadReconcileFromReader(t *testing.T) {
+	var (
+		auth     string
+		fileName string
+		content  string
+		message  string
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token/password" {
+			w.Write([]byte(`{"accessToken":"fresh-token","expiresIn":1800}`))
+			return
+		}
+		auth = r.Header.Get("Authorization")
+		f, h, err := r.FormFile("file")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		b, _ := ioutil.ReadAll(f)
+		fileName = h.Filename
+		content = string(b)
+		message = r.FormValue("message")
+		w.Write([]byte(`{"data":{"accepted":2,"rejected":1,"errors":[{"row":3,"respondentId":"r3","message":"unknown respondent"}]},"status":{"message":"success"}}`))
+	}))
+	defer ts.Close()
+
+	client := samplify.NewClient("", "", "", &samplify.ClientOptions{APIBaseURL: ts.URL, AuthURL: ts.URL})
+	res, err := client.UploadReconcileFromReader("test-prj-id", strings.NewReader("r1\nr2\nr3\n"), "ids.csv", "corrections", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth != "Bearer fresh-token" {
+		t.Errorf("expected the expired session to be renewed, got authorization %q", auth)
+	}
+	if fileName != "ids.csv" || content != "r1\nr2\nr3\n" || message != "corrections" {
+		t.Errorf("unexpected form data: %q %q %q", fileName, content, message)
+	}
+	if res.Result == nil || res.Result.Accepted != 2 || res.Result.Rejected != 1 || len(res.Result.Errors) != 1 {
+		t.Fatalf("unexpected result: %+v", res.Result)
+	}
+	if res.Result.Errors[0].RespondentID != "r3" {
+		t.Errorf("expected rejected respondent r3, got %s", res.Result.Errors[0].RespondentID)
+	}
+}
+
+// closingReader is a multipart.File read from a string.
+type closingReader struct {
+	*strings.Reader
+}
+
+func (closingReader) Close() error { return nil }
+
+func TestUploadReconcileOptions(t *testing.T) {
+	var query string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(`{"data":{"accepted":1}}`))
+	}))
+	defer ts.Close()
+
+	now := time.Now()
+	client := samplify.NewClient("", "", "", &samplify.ClientOptions{APIBaseURL: ts.URL, AuthURL: ts.URL})
+	client.Auth = samplify.TokenResponse{AccessToken: "test", Acquired: &now, ExpiresIn: 1800}
+	_, err := client.UploadReconcile("test-prj-id", closingReader{strings.NewReader("r1\n")}, "ids.csv", "corrections", &samplify.QueryOptions{Scope: "company"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "scope=company" {
+		t.Errorf("got query %q, want scope=company", query)
+	}
+}
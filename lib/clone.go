@@ -0,0 +1,238 @@
+package samplify
+
+import (
+	"context"
+)
+
+// CloneOverrides customises the project created by CloneProject. Every hook is optional.
+type CloneOverrides struct {
+	// Title returns the title of the new project from the title of the source project.
+	Title func(title string) string
+	// LineItemTitle returns the title of a new line item from the title of the source line item.
+	LineItemTitle func(title string) string
+	// ExtLineItemID returns the external id of a new line item from the id of the source line item.
+	ExtLineItemID func(extLineItemID string) string
+	// Criteria is called last and may change anything before the project is created.
+	Criteria func(criteria *CreateProjectCriteria) error
+}
+
+// ToCreateCriteria returns the criteria to create a new project with the same settings and line items as p.
+func (p *Project) ToCreateCriteria() *CreateProjectCriteria {
+	criteria := &CreateProjectCriteria{
+		ExtProjectID:       p.ExtProjectID,
+		Title:              p.Title,
+		NotificationEmails: append([]string{}, p.NotificationEmails...),
+		JobNumber:          p.JobNumber,
+		Devices:            append([]DeviceType{}, p.Devices...),
+		Category:           p.Category.Clone(),
+		LineItems:          make([]*CreateLineItemCriteria, 0, len(p.LineItems)),
+	}
+	if p.Exclusions != nil {
+		criteria.Exclusions = &Exclusions{
+			Type: p.Exclusions.Type,
+			List: append([]string{}, p.Exclusions.List...),
+		}
+	}
+	for _, l := range p.LineItems {
+		criteria.LineItems = append(criteria.LineItems, l.ToCreateCriteria())
+	}
+	return criteria
+}
+
+// ToCreateCriteria returns the criteria to create a new line item with the same settings as l. The ids and statuses
+// the API assigned to the quota groups and cells are not copied, nor is a field schedule that has already started:
+// its days in field are used instead.
+func (l *LineItem) ToCreateCriteria() *CreateLineItemCriteria {
+	criteria := &CreateLineItemCriteria{
+		ExtLineItemID:       l.ExtLineItemID,
+		Title:               l.Title,
+		CountryISOCode:      l.CountryISOCode,
+		LanguageISOCode:     l.LanguageISOCode,
+		SurveyURL:           optionalString(l.SurveyURL),
+		SurveyTestURL:       optionalString(l.SurveyTestURL),
+		IndicativeIncidence: l.IndicativeIncidence,
+		DaysInField:         l.DaysInField,
+		LengthOfInterview:   l.LengthOfInterview,
+		QuotaPlan:           l.QuotaPlan.Clone().StripIDs(),
+		SurveyURLParams:     cloneURLParameters(l.SurveyURLParams),
+		SurveyTestingNotes:  optionalString(l.SurveyTestingNotes),
+	}
+	switch {
+	case l.FieldSchedule.started():
+		// A new line item cannot start in the past: the schedule gives way to its length in days.
+		if criteria.DaysInField == 0 {
+			criteria.DaysInField = l.FieldSchedule.Days()
+		}
+	case l.FieldSchedule != nil:
+		schedule := *l.FieldSchedule
+		criteria.FieldSchedule = &schedule
+	}
+	if l.DeliveryType != nil {
+		deliveryType := *l.DeliveryType
+		criteria.DeliveryType = &deliveryType
+	}
+	for _, s := range l.Sources {
+		source := *s
+		criteria.Sources = append(criteria.Sources, &source)
+	}
+	for _, t := range l.Targets {
+		criteria.Targets = append(criteria.Targets, t.Clone())
+	}
+	return criteria
+}
+
+// Clone returns a deep copy of the category.
+func (c *Category) Clone() *Category {
+	if c == nil {
+		return nil
+	}
+	res := &Category{SurveyTopic: append([]string{}, c.SurveyTopic...)}
+	if c.StudyType != nil {
+		studyType := append([]string{}, *c.StudyType...)
+		res.StudyType = &studyType
+	}
+	if c.StudyRequirements != nil {
+		requirements := append([]string{}, *c.StudyRequirements...)
+		res.StudyRequirements = &requirements
+	}
+	return res
+}
+
+// Clone returns a deep copy of the target.
+func (t *LineItemTarget) Clone() *LineItemTarget {
+	res := &LineItemTarget{Type: t.Type}
+	if t.Count != nil {
+		count := *t.Count
+		res.Count = &count
+	}
+	if t.DailyLimit != nil {
+		limit := *t.DailyLimit
+		res.DailyLimit = &limit
+	}
+	return res
+}
+
+// Clone returns a deep copy of the quota plan.
+func (qp *QuotaPlan) Clone() *QuotaPlan {
+	if qp == nil {
+		return nil
+	}
+	res := &QuotaPlan{}
+	for _, f := range qp.Filters {
+		filter := &QuotaFilters{AttributeID: f.AttributeID, Options: append([]string{}, f.Options...)}
+		if f.Operator != nil {
+			operator := *f.Operator
+			filter.Operator = &operator
+		}
+		res.Filters = append(res.Filters, filter)
+	}
+	for _, g := range qp.QuotaGroups {
+		group := &QuotaGroup{QuotaGroupID: cloneString(g.QuotaGroupID), Name: cloneString(g.Name)}
+		for _, c := range g.QuotaCells {
+			group.QuotaCells = append(group.QuotaCells, c.Clone())
+		}
+		res.QuotaGroups = append(res.QuotaGroups, group)
+	}
+	return res
+}
+
+// Clone returns a deep copy of the quota cell.
+func (c *QuotaCell) Clone() *QuotaCell {
+	res := &QuotaCell{QuotaCellID: cloneString(c.QuotaCellID)}
+	for _, n := range c.QuotaNodes {
+		res.QuotaNodes = append(res.QuotaNodes, &QuotaNode{AttributeID: n.AttributeID, Options: append([]string{}, n.Options...)})
+	}
+	if c.Perc != nil {
+		perc := *c.Perc
+		res.Perc = &perc
+	}
+	if c.Count != nil {
+		count := *c.Count
+		res.Count = &count
+	}
+	if c.Status != nil {
+		status := *c.Status
+		res.Status = &status
+	}
+	return res
+}
+
+// StripIDs removes the ids and statuses assigned by the API to the quota groups and cells, so that the plan can be
+// used to create a new line item. It returns the plan itself.
+func (qp *QuotaPlan) StripIDs() *QuotaPlan {
+	if qp == nil {
+		return nil
+	}
+	for _, g := range qp.QuotaGroups {
+		g.QuotaGroupID = nil
+		for _, c := range g.QuotaCells {
+			c.QuotaCellID = nil
+			c.Status = nil
+		}
+	}
+	return qp
+}
+
+// CloneProjectWithContext creates a new project with the settings, line items and quota plans of an existing one.
+func (c *Client) CloneProjectWithContext(ctx context.Context, srcExtProjectID, newExtProjectID string, overrides *CloneOverrides) (*ProjectResponse, error) {
+	err := ValidateNotEmpty(srcExtProjectID, newExtProjectID)
+	if err != nil {
+		return nil, err
+	}
+	src, err := c.GetProjectByWithContext(ctx, srcExtProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if src.Project == nil {
+		return nil, ErrRequiredFieldEmpty
+	}
+	criteria := src.Project.ToCreateCriteria()
+	criteria.ExtProjectID = newExtProjectID
+	if overrides != nil {
+		if overrides.Title != nil {
+			criteria.Title = overrides.Title(criteria.Title)
+		}
+		for _, l := range criteria.LineItems {
+			if overrides.LineItemTitle != nil {
+				l.Title = overrides.LineItemTitle(l.Title)
+			}
+			if overrides.ExtLineItemID != nil {
+				l.ExtLineItemID = overrides.ExtLineItemID(l.ExtLineItemID)
+			}
+		}
+		if overrides.Criteria != nil {
+			if err := overrides.Criteria(criteria); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return c.CreateProjectWithContext(ctx, criteria)
+}
+
+// CloneProject creates a new project with the settings, line items and quota plans of an existing one.
+func (c *Client) CloneProject(srcExtProjectID, newExtProjectID string, overrides *CloneOverrides) (*ProjectResponse, error) {
+	return c.CloneProjectWithContext(context.Background(), srcExtProjectID, newExtProjectID, overrides)
+}
+
+func optionalString(s string) *string {
+	if len(s) == 0 {
+		return nil
+	}
+	return &s
+}
+
+func cloneString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := *s
+	return &v
+}
+
+func cloneURLParameters(params []*URLParameter) []*URLParameter {
+	var res []*URLParameter
+	for _, p := range params {
+		res = append(res, &URLParameter{Key: p.Key, Values: append([]string{}, p.Values...)})
+	}
+	return res
+}
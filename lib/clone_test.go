@@ -0,0 +1,137 @@
+package samplify_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+const cloneSourceProject = `{"data": {
+	"extProjectId": "src",
+	"title": "Wave 1",
+	"state": "CLOSED",
+	"notificationEmails": ["api-test@researchnow.com"],
+	"devices": ["mobile"],
+	"category": {"surveyTopic": ["AUTOMOTIVE"]},
+	"exclusions": {"type": "PROJECT", "list": ["old"]},
+	"lineItems": [{
+		"extLineItemId": "li-w1",
+		"state": "CLOSED",
+		"title": "US Wave 1",
+		"countryISOCode": "US",
+		"languageISOCode": "en",
+		"surveyURL": "www.mysurvey.com/live/survey",
+		"indicativeIncidence": 20,
+		"daysInField": 20,
+		"lengthOfInterview": 10,
+		"surveyURLParams": [{"key": "pid", "values": ["1"]}],
+		"targets": [{"count": 100, "type": "COMPLETE"}],
+		"quotaPlan": {"quotaGroups": [{"quotaGroupId": "g1", "name": "gender", "quotaCells": [
+			{"quotaCellId": "1", "quotaNodes": [{"attributeId": "11", "options": ["1"]}], "perc": 50, "status": "PAUSED"},
+			{"quotaCellId": "2", "quotaNodes": [{"attributeId": "11", "options": ["2"]}], "perc": 50, "status": "LAUNCHED"}
+		]}]}
+	}]
+}}`
+
+func TestCloneProject(t *testing.T) {
+	var created *samplify.CreateProjectCriteria
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/projects/src":
+			w.Write([]byte(cloneSourceProject))
+		case r.Method == "POST" && r.URL.Path == "/projects":
+			b, _ := ioutil.ReadAll(r.Body)
+			created = &samplify.CreateProjectCriteria{}
+			json.Unmarshal(b, created)
+			w.Write([]byte(`{"data": {"extProjectId": "dst"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client := samplify.NewClient("", "", "", &samplify.ClientOptions{APIBaseURL: ts.URL, AuthURL: ts.URL})
+	client.Auth = getAuth()
+	_, err := client.CloneProject("src", "dst", &samplify.CloneOverrides{
+		Title:         func(title string) string { return strings.Replace(title, "Wave 1", "Wave 2", 1) },
+		LineItemTitle: func(title string) string { return strings.Replace(title, "Wave 1", "Wave 2", 1) },
+		ExtLineItemID: func(id string) string { return strings.Replace(id, "w1", "w2", 1) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created == nil {
+		t.Fatal("the project was not created")
+	}
+	if created.ExtProjectID != "dst" || created.Title != "Wave 2" || created.Exclusions == nil || created.Category == nil {
+		t.Errorf("unexpected project criteria %+v", created)
+	}
+	if len(created.LineItems) != 1 {
+		t.Fatalf("got %d line items, want 1", len(created.LineItems))
+	}
+	l := created.LineItems[0]
+	if l.ExtLineItemID != "li-w2" || l.Title != "US Wave 2" || l.SurveyURL == nil || len(l.Targets) != 1 || len(l.SurveyURLParams) != 1 {
+		t.Errorf("unexpected line item criteria %+v", l)
+	}
+	g := l.QuotaPlan.QuotaGroups[0]
+	if g.QuotaGroupID != nil || g.QuotaCells[0].QuotaCellID != nil || g.QuotaCells[0].Status != nil || *g.QuotaCells[1].Perc != 50 {
+		t.Errorf("expected the quota plan to be copied without server ids, got %+v", g)
+	}
+}
+
+func TestCloneProjectSchedule(t *testing.T) {
+	tables := []struct {
+		name     string
+		schedule string
+		days     int64
+		kept     bool
+	}{
+		{"Case 1: started schedule", `{"startTime": "2020-01-06T09:00:00-05:00", "endTime": "2020-01-16T09:00:00-05:00"}`, 10, false},
+		{"Case 2: future schedule", `{"startTime": "2099-01-06T09:00:00-05:00", "endTime": "2099-01-16T09:00:00-05:00"}`, 0, true},
+	}
+
+	for _, table := range tables {
+		source := strings.Replace(cloneSourceProject, `"daysInField": 20,`,
+			`"daysInField": 0, "fieldSchedule": `+table.schedule+`,`, 1)
+		var created *samplify.CreateProjectCriteria
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "GET" {
+				w.Write([]byte(source))
+				return
+			}
+			created = &samplify.CreateProjectCriteria{}
+			json.NewDecoder(r.Body).Decode(created)
+			w.Write([]byte(`{"data": {"extProjectId": "dst"}}`))
+		}))
+		client := samplify.NewClient("", "", "", &samplify.ClientOptions{APIBaseURL: ts.URL, AuthURL: ts.URL})
+		client.Auth = getAuth()
+		_, err := client.CloneProject("src", "dst", nil)
+		ts.Close()
+		if err != nil {
+			t.Errorf("%s: %v", table.name, err)
+			continue
+		}
+		l := created.LineItems[0]
+		if (l.FieldSchedule != nil) != table.kept || l.DaysInField != table.days {
+			t.Errorf("%s: got schedule %v and %d days in field", table.name, l.FieldSchedule, l.DaysInField)
+		}
+	}
+}
+
+func TestQuotaPlanClone(t *testing.T) {
+	id, perc := "1", 50.0
+	qp := &samplify.QuotaPlan{QuotaGroups: []*samplify.QuotaGroup{{QuotaCells: []*samplify.QuotaCell{{QuotaCellID: &id, Perc: &perc}}}}}
+	clone := qp.Clone().StripIDs()
+	if qp.QuotaGroups[0].QuotaCells[0].QuotaCellID == nil {
+		t.Error("stripping the ids of a clone changed the original plan")
+	}
+	*clone.QuotaGroups[0].QuotaCells[0].Perc = 25
+	if *qp.QuotaGroups[0].QuotaCells[0].Perc != 50 {
+		t.Error("changing the clone changed the original plan")
+	}
+}
@@ -0,0 +1,283 @@
+package samplify
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrInvoiceNotFound is returned when no invoice summary exists for the requested project.
+var ErrInvoiceNotFound = errors.New("no invoice found for the project")
+
+// DefaultCostTolerance is the largest difference between billed and incurred costs that is still considered a match.
+const DefaultCostTolerance = 0.01
+
+// DiscrepancyStatus ...
+type DiscrepancyStatus string
+
+// DiscrepancyStatus values
+const (
+	DiscrepancyStatusMatch            DiscrepancyStatus = "MATCH"
+	DiscrepancyStatusMismatch         DiscrepancyStatus = "MISMATCH"
+	DiscrepancyStatusCurrencyMismatch DiscrepancyStatus = "CURRENCY_MISMATCH"
+	DiscrepancyStatusNotInvoiced      DiscrepancyStatus = "NOT_INVOICED"
+	DiscrepancyStatusNotReported      DiscrepancyStatus = "NOT_REPORTED"
+)
+
+// CostDiscrepancy compares the billed amount of a line item, or of one of its cost types, with the costs reported
+// during fielding. CostType is empty for the line item totals. Difference is BilledCost - IncurredCost and is only
+// computed when both amounts are in the same currency.
+type CostDiscrepancy struct {
+	ExtLineItemID   string            `json:"extLineItemId"`
+	Title           string            `json:"title"`
+	CostType        CostType          `json:"costType,omitempty"`
+	ReportCurrency  string            `json:"reportCurrency"`
+	InvoiceCurrency string            `json:"invoiceCurrency"`
+	EstimatedCost   float64           `json:"estimatedCost"`
+	IncurredCost    float64           `json:"incurredCost"`
+	BilledCost      float64           `json:"billedCost"`
+	Difference      float64           `json:"difference"`
+	Status          DiscrepancyStatus `json:"status"`
+}
+
+// CostReconciliation is the result of comparing a project's invoice with its detailed reports.
+type CostReconciliation struct {
+	ExtProjectID string             `json:"extProjectId"`
+	Tolerance    float64            `json:"tolerance"`
+	Project      *CostDiscrepancy   `json:"project"`
+	LineItems    []*CostDiscrepancy `json:"lineItems"`
+}
+
+// HasDiscrepancies returns true if any of the compared amounts do not match.
+func (r *CostReconciliation) HasDiscrepancies() bool {
+	if r.Project != nil && r.Project.Status != DiscrepancyStatusMatch {
+		return true
+	}
+	for _, l := range r.LineItems {
+		if l.Status != DiscrepancyStatusMatch {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteJSON writes the reconciliation as indented JSON.
+func (r *CostReconciliation) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteCSV writes one row per compared amount, starting with the project totals.
+func (r *CostReconciliation) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"extLineItemId", "title", "costType", "reportCurrency", "invoiceCurrency",
+		"estimatedCost", "incurredCost", "billedCost", "difference", "status"})
+	if err != nil {
+		return err
+	}
+	rows := r.LineItems
+	if r.Project != nil {
+		rows = append([]*CostDiscrepancy{r.Project}, rows...)
+	}
+	for _, d := range rows {
+		err = cw.Write([]string{d.ExtLineItemID, d.Title, string(d.CostType), d.ReportCurrency, d.InvoiceCurrency,
+			formatAmount(d.EstimatedCost), formatAmount(d.IncurredCost), formatAmount(d.BilledCost),
+			formatAmount(d.Difference), string(d.Status)})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// CompareCosts compares the invoice summary of a project with its detailed project report. lineItemReports are
+// optional and, when given, are used instead of the line items of the project report so that the per type
+// DetailedCost blocks can be compared as well.
+func CompareCosts(invoice *InvoiceSummary, report *DetailedProjectReport, lineItemReports []*DetailedLineItemReport, tolerance float64) *CostReconciliation {
+	res := &CostReconciliation{
+		ExtProjectID: report.ExtProjectID,
+		Tolerance:    tolerance,
+		Project: compareCost(tolerance, &CostDiscrepancy{
+			Title:           report.Title,
+			ReportCurrency:  report.Cost.Currency,
+			InvoiceCurrency: invoice.Currency,
+			EstimatedCost:   report.Cost.EstimatedCost,
+			IncurredCost:    report.Cost.IncurredCost,
+			BilledCost:      invoice.TotalCost,
+		}, true, true),
+	}
+
+	reports := lineItemReports
+	if len(reports) == 0 {
+		reports = report.LineItems
+	}
+	reported := make(map[string]bool, len(reports))
+	for _, lr := range reports {
+		reported[lr.ExtLineItemID] = true
+		currency := lr.Cost.Currency
+		if len(currency) == 0 {
+			currency = report.Cost.Currency
+		}
+		il := invoice.LineItem(lr.ExtLineItemID)
+		d := &CostDiscrepancy{
+			ExtLineItemID:  lr.ExtLineItemID,
+			Title:          lr.Title,
+			ReportCurrency: currency,
+			EstimatedCost:  lr.Cost.EstimatedCost,
+			IncurredCost:   lr.Cost.IncurredCost,
+		}
+		if il != nil {
+			d.InvoiceCurrency = invoiceCurrency(invoice, il)
+			d.BilledCost = il.TotalCost
+		}
+		res.LineItems = append(res.LineItems, compareCost(tolerance, d, true, il != nil))
+		res.LineItems = append(res.LineItems, compareDetailedCosts(tolerance, invoice, lr, il, currency)...)
+	}
+
+	for _, il := range invoice.LineItems {
+		if reported[il.ExtLineItemID] {
+			continue
+		}
+		res.LineItems = append(res.LineItems, compareCost(tolerance, &CostDiscrepancy{
+			ExtLineItemID:   il.ExtLineItemID,
+			Title:           il.Title,
+			InvoiceCurrency: invoiceCurrency(invoice, il),
+			BilledCost:      il.TotalCost,
+		}, false, true))
+	}
+	return res
+}
+
+func compareDetailedCosts(tolerance float64, invoice *InvoiceSummary, lr *DetailedLineItemReport, il *InvoiceLineItem, currency string) []*CostDiscrepancy {
+	var res []*CostDiscrepancy
+	billed := map[CostType]*InvoiceDetailedCost{}
+	var billedTypes []CostType
+	if il != nil {
+		for _, dc := range il.DetailedCost {
+			billed[dc.Type] = dc
+			billedTypes = append(billedTypes, dc.Type)
+		}
+	}
+	seen := map[CostType]bool{}
+	for _, dc := range lr.Cost.DetailedCost {
+		seen[dc.Type] = true
+		b, ok := billed[dc.Type]
+		d := &CostDiscrepancy{
+			ExtLineItemID:  lr.ExtLineItemID,
+			Title:          dc.Title,
+			CostType:       dc.Type,
+			ReportCurrency: currency,
+			EstimatedCost:  dc.EstimatedCost,
+			IncurredCost:   dc.IncurredCost,
+		}
+		if ok {
+			d.InvoiceCurrency = invoiceCurrency(invoice, il)
+			d.BilledCost = b.TotalCost
+		}
+		res = append(res, compareCost(tolerance, d, true, ok))
+	}
+	for _, t := range billedTypes {
+		if seen[t] {
+			continue
+		}
+		b := billed[t]
+		res = append(res, compareCost(tolerance, &CostDiscrepancy{
+			ExtLineItemID:   lr.ExtLineItemID,
+			Title:           b.Title,
+			CostType:        t,
+			ReportCurrency:  currency,
+			InvoiceCurrency: invoiceCurrency(invoice, il),
+			BilledCost:      b.TotalCost,
+		}, false, true))
+	}
+	return res
+}
+
+func compareCost(tolerance float64, d *CostDiscrepancy, isReported, isInvoiced bool) *CostDiscrepancy {
+	switch {
+	case !isInvoiced:
+		d.Status = DiscrepancyStatusNotInvoiced
+	case !isReported:
+		d.Status = DiscrepancyStatusNotReported
+	case !strings.EqualFold(d.ReportCurrency, d.InvoiceCurrency):
+		d.Status = DiscrepancyStatusCurrencyMismatch
+	default:
+		d.Difference = roundAmount(d.BilledCost - d.IncurredCost)
+		d.Status = DiscrepancyStatusMatch
+		if math.Abs(d.Difference) > tolerance {
+			d.Status = DiscrepancyStatusMismatch
+		}
+	}
+	return d
+}
+
+func invoiceCurrency(invoice *InvoiceSummary, il *InvoiceLineItem) string {
+	if il != nil && len(il.Currency) > 0 {
+		return il.Currency
+	}
+	return invoice.Currency
+}
+
+func roundAmount(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// ReconcileInvoiceCostsWithContext fetches the invoice summary and the detailed reports of a project and compares
+// the billed amounts with the costs reported during fielding. Every page of invoice summaries is read, as the API may
+// return summaries of other projects despite the extProjectId filter.
+func (c *Client) ReconcileInvoiceCostsWithContext(ctx context.Context, extProjectID string, tolerance float64) (*CostReconciliation, error) {
+	err := ValidateNotEmpty(extProjectID)
+	if err != nil {
+		return nil, err
+	}
+	var invoice *InvoiceSummary
+	err = readPages(func(options *QueryOptions) (int, int64, error) {
+		options.ExtProjectId = &extProjectID
+		page, err := c.GetInvoiceSummariesWithContext(ctx, options)
+		if err != nil {
+			return 0, 0, err
+		}
+		for _, s := range page.List {
+			if invoice == nil && s.ExtProjectID == extProjectID {
+				invoice = s
+			}
+		}
+		return len(page.List), page.Meta.Total, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if invoice == nil {
+		return nil, ErrInvoiceNotFound
+	}
+	report, err := c.GetDetailedProjectReportWithContext(ctx, extProjectID)
+	if err != nil {
+		return nil, err
+	}
+	lineItemReports := make([]*DetailedLineItemReport, 0, len(report.Report.LineItems))
+	for _, l := range report.Report.LineItems {
+		lr, err := c.GetDetailedLineItemReportWithContext(ctx, extProjectID, l.ExtLineItemID)
+		if err != nil {
+			return nil, err
+		}
+		lineItemReports = append(lineItemReports, &lr.Report)
+	}
+	return CompareCosts(invoice, &report.Report, lineItemReports, tolerance), nil
+}
+
+// ReconcileInvoiceCosts fetches the invoice summary and the detailed reports of a project and compares the billed
+// amounts with the costs reported during fielding.
+func (c *Client) ReconcileInvoiceCosts(extProjectID string, tolerance float64) (*CostReconciliation, error) {
+	return c.ReconcileInvoiceCostsWithContext(context.Background(), extProjectID, tolerance)
+}
@@ -0,0 +1,161 @@
+package samplify_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+func TestCompareCosts(t *testing.T) {
+	invoice := &samplify.InvoiceSummary{
+		ExtProjectID: "prj",
+		Currency:     "USD",
+		TotalCost:    250,
+		LineItems: []*samplify.InvoiceLineItem{
+			{ExtLineItemID: "li-1", TotalCost: 100, DetailedCost: []*samplify.InvoiceDetailedCost{
+				{Type: samplify.CostTypeBase, TotalCost: 80},
+				{Type: samplify.CostTypePremium, TotalCost: 20},
+			}},
+			{ExtLineItemID: "li-2", Currency: "EUR", TotalCost: 100},
+			{ExtLineItemID: "li-3", TotalCost: 50},
+		},
+	}
+	report := &samplify.DetailedProjectReport{
+		ExtProjectID: "prj",
+		Cost:         samplify.Cost{Currency: "USD", EstimatedCost: 220, IncurredCost: 200},
+	}
+	lineItems := []*samplify.DetailedLineItemReport{
+		{ExtLineItemID: "li-1", Cost: samplify.Cost{Currency: "USD", IncurredCost: 100.004, DetailedCost: []*samplify.DetailedCost{
+			{Type: samplify.CostTypeBase, IncurredCost: 75},
+			{Type: samplify.CostTypePremium, IncurredCost: 20},
+		}}},
+		{ExtLineItemID: "li-2", Cost: samplify.Cost{Currency: "USD", IncurredCost: 100}},
+		{ExtLineItemID: "li-4", Cost: samplify.Cost{Currency: "USD", IncurredCost: 10}},
+	}
+
+	res := samplify.CompareCosts(invoice, report, lineItems, samplify.DefaultCostTolerance)
+
+	if res.Project.Status != samplify.DiscrepancyStatusMismatch || res.Project.Difference != 50 {
+		t.Errorf("unexpected project totals: %+v", res.Project)
+	}
+	expected := []struct {
+		id       string
+		costType samplify.CostType
+		status   samplify.DiscrepancyStatus
+	}{
+		{"li-1", "", samplify.DiscrepancyStatusMatch},
+		{"li-1", samplify.CostTypeBase, samplify.DiscrepancyStatusMismatch},
+		{"li-1", samplify.CostTypePremium, samplify.DiscrepancyStatusMatch},
+		{"li-2", "", samplify.DiscrepancyStatusCurrencyMismatch},
+		{"li-4", "", samplify.DiscrepancyStatusNotInvoiced},
+		{"li-3", "", samplify.DiscrepancyStatusNotReported},
+	}
+	if len(res.LineItems) != len(expected) {
+		t.Fatalf("got %d rows, want %d", len(res.LineItems), len(expected))
+	}
+	for i, e := range expected {
+		d := res.LineItems[i]
+		if d.ExtLineItemID != e.id || d.CostType != e.costType || d.Status != e.status {
+			t.Errorf("row %d: got %s/%s/%s, want %s/%s/%s", i, d.ExtLineItemID, d.CostType, d.Status, e.id, e.costType, e.status)
+		}
+	}
+	if !res.HasDiscrepancies() {
+		t.Error("expected discrepancies")
+	}
+
+	buf := &bytes.Buffer{}
+	if err := res.WriteCSV(buf); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != len(expected)+2 {
+		t.Errorf("got %d csv lines, want %d", len(lines), len(expected)+2)
+	}
+}
+
+// invoiceServer serves others invoice summaries of other projects, followed by the one of prj when invoiced is set,
+// by offset and limit. The summaries are only served for extProjectId=prj.
+func invoiceServer(t *testing.T, others int, invoiced bool, requests *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/projects/invoices/summary":
+			*requests = append(*requests, r.URL.RawQuery)
+			q := r.URL.Query()
+			if q.Get("extProjectId") != "prj" {
+				t.Errorf("unexpected query %q", r.URL.RawQuery)
+			}
+			offset, _ := strconv.Atoi(q.Get("offset"))
+			limit, err := strconv.Atoi(q.Get("limit"))
+			if err != nil {
+				limit = 10
+			}
+			count := others
+			if invoiced {
+				count++
+			}
+			var items []string
+			for i := offset; i < count && i < offset+limit; i++ {
+				id := fmt.Sprintf("other-%d", i)
+				if i == others {
+					id = "prj"
+				}
+				items = append(items, fmt.Sprintf(`{"extProjectId": "%s", "currency": "USD", "totalCost": 100,
+					"lineItems": [{"extLineItemId": "li-1", "totalCost": 100}]}`, id))
+			}
+			fmt.Fprintf(w, `{"data": [%s], "meta": {"total": %d}}`, strings.Join(items, ","), count)
+		case "/projects/prj/detailedReport":
+			fmt.Fprint(w, `{"data": {"extProjectId": "prj", "cost": {"currency": "USD", "incurredCost": 100},
+				"lineItems": [{"extLineItemId": "li-1"}]}}`)
+		case "/projects/prj/lineItems/li-1/detailedReport":
+			fmt.Fprint(w, `{"data": {"extLineItemId": "li-1", "cost": {"currency": "USD", "incurredCost": 100}}}`)
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestReconcileInvoiceCosts(t *testing.T) {
+	tests := []struct {
+		name     string
+		others   int
+		invoiced bool
+		requests []string
+		err      error
+	}{
+		{"first page", 10, true, []string{"limit=1000&extProjectId=prj"}, nil},
+		{"second page", 1200, true,
+			[]string{"limit=1000&extProjectId=prj", "offset=1000&limit=1000&extProjectId=prj"}, nil},
+		{"not invoiced", 1200, false,
+			[]string{"limit=1000&extProjectId=prj", "offset=1000&limit=1000&extProjectId=prj"}, samplify.ErrInvoiceNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			ts := invoiceServer(t, tt.others, tt.invoiced, &requests)
+			defer ts.Close()
+			client := samplify.NewClient("", "", "", &samplify.ClientOptions{APIBaseURL: ts.URL, AuthURL: ts.URL})
+			client.Auth = getAuth()
+
+			res, err := client.ReconcileInvoiceCosts("prj", samplify.DefaultCostTolerance)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if fmt.Sprint(requests) != fmt.Sprint(tt.requests) {
+				t.Errorf("got requests %v, want %v", requests, tt.requests)
+			}
+			if tt.err != nil {
+				return
+			}
+			if res.HasDiscrepancies() || len(res.LineItems) != 1 || res.LineItems[0].BilledCost != 100 {
+				t.Errorf("unexpected reconciliation: %+v %+v", res.Project, res.LineItems)
+			}
+		})
+	}
+}
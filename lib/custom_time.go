@@ -23,6 +23,14 @@ func (ct *CustomTime) UnmarshalJSON(b []byte) (err error) {
 	return
 }
 
+// MarshalJSON ...
+func (ct CustomTime) MarshalJSON() ([]byte, error) {
+	if ct.Time.IsZero() {
+		return []byte("null"), nil
+	}
+	return []byte("\"" + ct.Time.Format(ctLayout) + "\""), nil
+}
+
 var nilTime = (time.Time{}).UnixNano()
 
 // IsSet ...
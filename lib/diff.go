@@ -0,0 +1,275 @@
+package samplify
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ChangeOp ...
+type ChangeOp string
+
+// ChangeOp values
+const (
+	ChangeOpUpdate ChangeOp = "update"
+	ChangeOpAdd    ChangeOp = "add"
+	// ChangeOpUnmanaged is an existing line item missing from the desired state, which is left as it is.
+	ChangeOpUnmanaged ChangeOp = "unmanaged"
+)
+
+// FieldChange is a single difference between the current and the desired state of a project or line item.
+// Old and New hold the JSON encoding of the values.
+type FieldChange struct {
+	Op   ChangeOp `json:"op"`
+	Path string   `json:"path"`
+	Old  string   `json:"old,omitempty"`
+	New  string   `json:"new,omitempty"`
+	// Blocked holds the reason why the change is not allowed in the current state, it is empty otherwise.
+	Blocked string `json:"blocked,omitempty"`
+}
+
+func (c *FieldChange) String() string {
+	s := fmt.Sprintf("+ %s", c.Path)
+	switch c.Op {
+	case ChangeOpUpdate:
+		s = fmt.Sprintf("~ %s: %s -> %s", c.Path, c.Old, c.New)
+	case ChangeOpUnmanaged:
+		s = fmt.Sprintf("? %s", c.Path)
+	}
+	if len(c.Blocked) > 0 {
+		s = fmt.Sprintf("%s (blocked: %s)", s, c.Blocked)
+	}
+	return s
+}
+
+// ProjectDiff is the result of comparing a project with its desired state.
+type ProjectDiff struct {
+	// Update holds only the changed fields and line items, it is nil when nothing changed.
+	Update *UpdateProjectCriteria `json:"update,omitempty"`
+	// NewLineItems are the desired line items the project does not have yet.
+	NewLineItems []*CreateLineItemCriteria `json:"newLineItems,omitempty"`
+	Changes      []*FieldChange            `json:"changes"`
+}
+
+// Blocked returns the changes that are not allowed in the current state.
+func (d *ProjectDiff) Blocked() []*FieldChange {
+	return blockedChanges(d.Changes)
+}
+
+// String lists the changes, one per line.
+func (d *ProjectDiff) String() string {
+	return changesString(d.Changes)
+}
+
+// rebalanceFields can still be changed on line items that are no longer updateable but can be rebalanced.
+var rebalanceFields = map[string]bool{
+	"quotaPlan":     true,
+	"targets":       true,
+	"daysInField":   true,
+	"fieldSchedule": true,
+}
+
+// DiffProject compares a project with the desired criteria and returns the minimal update. Optional fields that are
+// not set in desired are left unchanged. Line items of the project missing from desired are ignored since they
+// cannot be removed.
+func DiffProject(current *Project, desired *CreateProjectCriteria) *ProjectDiff {
+	diff := &ProjectDiff{}
+	update := &UpdateProjectCriteria{ExtProjectID: desired.ExtProjectID}
+	blocked := ""
+	if !current.IsUpdateable() {
+		blocked = fmt.Sprintf("project is %s", current.State)
+	}
+	changed := false
+	add := func(path string, old, new interface{}) bool {
+		c := compareValues(path, old, new)
+		if c == nil {
+			return false
+		}
+		c.Blocked = blocked
+		diff.Changes = append(diff.Changes, c)
+		changed = true
+		return true
+	}
+
+	if add("title", current.Title, desired.Title) {
+		update.Title = &desired.Title
+	}
+	if desired.NotificationEmails != nil && add("notificationEmails", sortedStrings(current.NotificationEmails), sortedStrings(desired.NotificationEmails)) {
+		update.NotificationEmails = &desired.NotificationEmails
+	}
+	if len(desired.JobNumber) > 0 && add("jobNumber", current.JobNumber, desired.JobNumber) {
+		update.JobNumber = &desired.JobNumber
+	}
+	if desired.Devices != nil && add("devices", sortedDevices(current.Devices), sortedDevices(desired.Devices)) {
+		update.Devices = &desired.Devices
+	}
+	if desired.Category != nil && add("category", current.Category, desired.Category) {
+		update.Category = desired.Category
+	}
+	if desired.Exclusions != nil && add("exclusions", current.Exclusions, desired.Exclusions) {
+		update.Exclusions = desired.Exclusions
+	}
+
+	existing := make(map[string]*LineItem, len(current.LineItems))
+	for _, l := range current.LineItems {
+		existing[l.ExtLineItemID] = l
+	}
+	var lineItems []*UpdateLineItemCriteria
+	for _, dl := range desired.LineItems {
+		l, ok := existing[dl.ExtLineItemID]
+		if !ok {
+			diff.NewLineItems = append(diff.NewLineItems, dl)
+			diff.Changes = append(diff.Changes, &FieldChange{Op: ChangeOpAdd, Path: LineItemPath(dl.ExtLineItemID), Blocked: blocked})
+			continue
+		}
+		lu, changes := DiffLineItem(l, dl)
+		if lu != nil {
+			lineItems = append(lineItems, lu)
+			changed = true
+		}
+		diff.Changes = append(diff.Changes, changes...)
+	}
+	if len(lineItems) > 0 {
+		update.LineItems = &lineItems
+	}
+	if changed {
+		diff.Update = update
+	}
+	return diff
+}
+
+// DiffLineItem compares a line item with the desired criteria and returns the minimal update, or nil if nothing
+// changed, along with the list of changes. Changes to fields that the state of the line item does not allow are
+// flagged as blocked: only the quota plan, targets and schedule of a line item that is no longer updateable can be
+// changed, as long as it can be rebalanced.
+func DiffLineItem(current *LineItem, desired *CreateLineItemCriteria) (*UpdateLineItemCriteria, []*FieldChange) {
+	update := &UpdateLineItemCriteria{ExtLineItemID: desired.ExtLineItemID}
+	var changes []*FieldChange
+	add := func(field string, old, new interface{}) bool {
+		c := compareValues(LineItemPath(desired.ExtLineItemID)+"."+field, old, new)
+		if c == nil {
+			return false
+		}
+		if !current.IsUpdateable() && !(rebalanceFields[field] && current.IsRebalanceable()) {
+			c.Blocked = fmt.Sprintf("line item is %s", current.State)
+		}
+		changes = append(changes, c)
+		return true
+	}
+
+	if add("title", current.Title, desired.Title) {
+		update.Title = &desired.Title
+	}
+	if add("countryISOCode", current.CountryISOCode, desired.CountryISOCode) {
+		update.CountryISOCode = &desired.CountryISOCode
+	}
+	if add("languageISOCode", current.LanguageISOCode, desired.LanguageISOCode) {
+		update.LanguageISOCode = &desired.LanguageISOCode
+	}
+	if desired.SurveyURL != nil && add("surveyURL", current.SurveyURL, *desired.SurveyURL) {
+		update.SurveyURL = desired.SurveyURL
+	}
+	if desired.SurveyTestURL != nil && add("surveyTestURL", current.SurveyTestURL, *desired.SurveyTestURL) {
+		update.SurveyTestURL = desired.SurveyTestURL
+	}
+	if add("indicativeIncidence", current.IndicativeIncidence, desired.IndicativeIncidence) {
+		update.IndicativeIncidence = &desired.IndicativeIncidence
+	}
+	if desired.DaysInField != 0 && add("daysInField", current.DaysInField, desired.DaysInField) {
+		update.DaysInField = &desired.DaysInField
+	}
+	if desired.FieldSchedule != nil && !desired.FieldSchedule.Equal(current.FieldSchedule) && add("fieldSchedule", current.FieldSchedule, desired.FieldSchedule) {
+		update.FieldSchedule = desired.FieldSchedule
+	}
+	if add("lengthOfInterview", current.LengthOfInterview, desired.LengthOfInterview) {
+		update.LengthOfInterview = &desired.LengthOfInterview
+	}
+	if desired.DeliveryType != nil && add("deliveryType", current.DeliveryType, desired.DeliveryType) {
+		update.DeliveryType = desired.DeliveryType
+	}
+	if desired.QuotaPlan != nil && add("quotaPlan", current.QuotaPlan.Clone().StripIDs(), desired.QuotaPlan.Clone().StripIDs()) {
+		update.QuotaPlan = desired.QuotaPlan
+	}
+	if desired.SurveyURLParams != nil && add("surveyURLParams", current.SurveyURLParams, desired.SurveyURLParams) {
+		update.SurveyURLParams = desired.SurveyURLParams
+	}
+	if desired.Sources != nil && add("sources", sourceIDs(current.Sources), sourceIDs(desired.Sources)) {
+		update.Sources = &desired.Sources
+	}
+	if desired.Targets != nil && add("targets", current.Targets, desired.Targets) {
+		update.Targets = desired.Targets
+	}
+	if desired.SurveyTestingNotes != nil && add("surveyTestingNotes", current.SurveyTestingNotes, *desired.SurveyTestingNotes) {
+		update.SurveyTestingNotes = desired.SurveyTestingNotes
+	}
+
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	return update, changes
+}
+
+// LineItemPath is the path of a line item in the changes of a project.
+func LineItemPath(extLineItemID string) string {
+	return fmt.Sprintf("lineItems[%s]", extLineItemID)
+}
+
+// compareValues returns an update change if the JSON encodings of old and new differ.
+func compareValues(path string, old, new interface{}) *FieldChange {
+	o, n := encodeValue(old), encodeValue(new)
+	if o == n {
+		return nil
+	}
+	return &FieldChange{Op: ChangeOpUpdate, Path: path, Old: o, New: n}
+}
+
+func encodeValue(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err.Error()
+	}
+	return string(b)
+}
+
+func blockedChanges(changes []*FieldChange) []*FieldChange {
+	var res []*FieldChange
+	for _, c := range changes {
+		if len(c.Blocked) > 0 {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+func changesString(changes []*FieldChange) string {
+	lines := make([]string, 0, len(changes))
+	for _, c := range changes {
+		lines = append(lines, c.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func sortedStrings(v []string) []string {
+	res := append([]string{}, v...)
+	sort.Strings(res)
+	return res
+}
+
+func sortedDevices(v []DeviceType) []string {
+	res := make([]string, 0, len(v))
+	for _, d := range v {
+		res = append(res, strings.ToLower(string(d)))
+	}
+	sort.Strings(res)
+	return res
+}
+
+func sourceIDs(sources []*LineItemSource) []int64 {
+	ids := make([]int64, 0, len(sources))
+	for _, s := range sources {
+		ids = append(ids, s.ID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -0,0 +1,96 @@
+package samplify_test
+
+import (
+	"testing"
+	"time"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+func TestDiffLineItem(t *testing.T) {
+	count, newCount := uint32(100), uint32(150)
+	notes := "notes"
+	current := func(state samplify.State) *samplify.LineItem {
+		return &samplify.LineItem{
+			LineItemHeader:      samplify.LineItemHeader{ExtLineItemID: "li", State: state},
+			Title:               "Title",
+			CountryISOCode:      "US",
+			LanguageISOCode:     "en",
+			IndicativeIncidence: 20,
+			LengthOfInterview:   10,
+			DaysInField:         5,
+			Targets:             []*samplify.LineItemTarget{{Count: &count, Type: samplify.TargetTypeComplete}},
+		}
+	}
+	desired := &samplify.CreateLineItemCriteria{
+		ExtLineItemID:       "li",
+		Title:               "Title",
+		CountryISOCode:      "US",
+		LanguageISOCode:     "en",
+		IndicativeIncidence: 20,
+		LengthOfInterview:   12,
+		Targets:             []*samplify.LineItemTarget{{Count: &newCount, Type: samplify.TargetTypeComplete}},
+		SurveyTestingNotes:  &notes,
+	}
+
+	tables := []struct {
+		name    string
+		state   samplify.State
+		blocked []string
+	}{
+		{"Case 1: provisioned line item allows every change", samplify.StateProvisioned, []string{"", "", ""}},
+		{"Case 2: launched line item only allows rebalancing", samplify.StateLaunched, []string{"line item is LAUNCHED", "", "line item is LAUNCHED"}},
+		{"Case 3: closed line item allows nothing", samplify.StateClosed, []string{"line item is CLOSED", "line item is CLOSED", "line item is CLOSED"}},
+	}
+
+	for _, table := range tables {
+		update, changes := samplify.DiffLineItem(current(table.state), desired)
+		if update == nil || len(changes) != len(table.blocked) {
+			t.Fatalf("%s: got %d changes, want %d", table.name, len(changes), len(table.blocked))
+		}
+		if update.Title != nil || update.DaysInField != nil || update.LengthOfInterview == nil || *update.LengthOfInterview != 12 || update.Targets == nil || update.SurveyTestingNotes == nil {
+			t.Errorf("%s: expected a minimal update, got %+v", table.name, update)
+		}
+		for i, c := range changes {
+			if c.Blocked != table.blocked[i] {
+				t.Errorf("%s: change %s got blocked `%s`, want `%s`", table.name, c.Path, c.Blocked, table.blocked[i])
+			}
+		}
+	}
+
+	if update, changes := samplify.DiffLineItem(current(samplify.StateProvisioned), current(samplify.StateProvisioned).ToCreateCriteria()); update != nil || changes != nil {
+		t.Errorf("expected no changes, got %v", changes)
+	}
+
+	scheduled := current(samplify.StateProvisioned)
+	scheduled.FieldSchedule = samplify.NewSchedule(time.Date(2030, 3, 1, 14, 0, 0, 0, time.UTC), 5)
+	offset := scheduled.ToCreateCriteria()
+	offset.FieldSchedule = scheduled.FieldSchedule.In(time.FixedZone("EST", -5*3600))
+	if _, changes := samplify.DiffLineItem(scheduled, offset); changes != nil {
+		t.Errorf("expected no changes for the same schedule in another time zone, got %v", changes)
+	}
+	offset.FieldSchedule = samplify.NewSchedule(time.Date(2030, 3, 2, 14, 0, 0, 0, time.UTC), 5)
+	if _, changes := samplify.DiffLineItem(scheduled, offset); len(changes) != 1 || changes[0].Path != "lineItems[li].fieldSchedule" {
+		t.Errorf("expected a field schedule change, got %v", changes)
+	}
+}
+
+func TestDiffProject(t *testing.T) {
+	current := &samplify.Project{
+		ProjectHeader: samplify.ProjectHeader{ExtProjectID: "prj", Title: "Old", State: samplify.StateClosed},
+		Devices:       []samplify.DeviceType{samplify.DeviceTypeMobile, samplify.DeviceTypeDesktop},
+	}
+	desired := current.ToCreateCriteria()
+	desired.Title = "New"
+	desired.Devices = []samplify.DeviceType{samplify.DeviceTypeDesktop, samplify.DeviceTypeMobile}
+	desired.LineItems = []*samplify.CreateLineItemCriteria{{ExtLineItemID: "li"}}
+
+	diff := samplify.DiffProject(current, desired)
+	expected := "~ title: \"Old\" -> \"New\" (blocked: project is CLOSED)\n+ lineItems[li] (blocked: project is CLOSED)"
+	if diff.String() != expected {
+		t.Errorf("got\n%s\nwant\n%s", diff.String(), expected)
+	}
+	if diff.Update == nil || diff.Update.Devices != nil || len(diff.NewLineItems) != 1 || len(diff.Blocked()) != 2 {
+		t.Errorf("unexpected diff %+v", diff)
+	}
+}
@@ -0,0 +1,177 @@
+package samplify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Errors reported for line items whose feasibility could not be computed
+var (
+	ErrFeasibilityFailed       = errors.New("feasibility failed")
+	ErrFeasibilityNotSupported = errors.New("feasibility not supported")
+)
+
+// Default polling intervals used by WaitForFeasibility
+const (
+	DefaultFeasibilityInitialInterval = 20 * time.Second
+	DefaultFeasibilityMaxInterval     = 2 * time.Minute
+	DefaultFeasibilityMultiplier      = 2
+)
+
+// FeasibilityWaitOptions configures WaitForFeasibility. Zero values use the defaults.
+type FeasibilityWaitOptions struct {
+	// InitialInterval is the delay before the second poll.
+	InitialInterval time.Duration
+	// MaxInterval caps the delay between two polls.
+	MaxInterval time.Duration
+	// Multiplier is applied to the delay after every poll that still has line items PROCESSING.
+	Multiplier float64
+	// Progress is called every time the status of a line item changes.
+	Progress func(*LineItemFeasibility)
+	// Updates receives the same values as Progress. Sends block until received or the context is done.
+	Updates chan<- *LineItemFeasibility
+}
+
+// LineItemFeasibility is the feasibility of one line item. Err is set when the status is FAILED or NOT_SUPPORTED.
+type LineItemFeasibility struct {
+	ExtLineItemID string
+	Feasibility   *Feasibility
+	Quote         Quote
+	Err           error
+}
+
+// Status returns the feasibility status of the line item. Line items without a feasibility are still PROCESSING.
+func (l *LineItemFeasibility) Status() FeasibilityStatus {
+	if l.Feasibility == nil {
+		return FeasibilityStatusProcessing
+	}
+	return l.Feasibility.Status
+}
+
+// Done tells whether the feasibility of the line item has stopped processing.
+func (l *LineItemFeasibility) Done() bool {
+	return l.Status() != FeasibilityStatusProcessing
+}
+
+// FeasibilityResult is the feasibility of every line item of a project.
+type FeasibilityResult struct {
+	ExtProjectID string
+	LineItems    []*LineItemFeasibility
+	Polls        int
+}
+
+// Done tells whether every line item has stopped processing.
+func (r *FeasibilityResult) Done() bool {
+	for _, l := range r.LineItems {
+		if !l.Done() {
+			return false
+		}
+	}
+	return true
+}
+
+// Feasible tells whether every line item is READY and feasible.
+func (r *FeasibilityResult) Feasible() bool {
+	for _, l := range r.LineItems {
+		if l.Status() != FeasibilityStatusReady || !l.Feasibility.Feasible {
+			return false
+		}
+	}
+	return true
+}
+
+// Errors returns the errors of the line items that FAILED or are NOT_SUPPORTED.
+func (r *FeasibilityResult) Errors() []error {
+	var errs []error
+	for _, l := range r.LineItems {
+		if l.Err != nil {
+			errs = append(errs, l.Err)
+		}
+	}
+	return errs
+}
+
+// WaitForFeasibilityWithContext polls every page of GetFeasibility until every line item of the project is READY,
+// NOT_SUPPORTED or FAILED, waiting longer between each poll. If the context is done first, the last result is returned
+// with the context error.
+func (c *Client) WaitForFeasibilityWithContext(ctx context.Context, extProjectID string, opts *FeasibilityWaitOptions) (*FeasibilityResult, error) {
+	o := FeasibilityWaitOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.InitialInterval <= 0 {
+		o.InitialInterval = DefaultFeasibilityInitialInterval
+	}
+	if o.MaxInterval <= 0 {
+		o.MaxInterval = DefaultFeasibilityMaxInterval
+	}
+	if o.Multiplier < 1 {
+		o.Multiplier = DefaultFeasibilityMultiplier
+	}
+
+	result := &FeasibilityResult{ExtProjectID: extProjectID}
+	previous := make(map[string]FeasibilityStatus)
+	interval := o.InitialInterval
+	for {
+		res, err := c.AllFeasibilityWithContext(ctx, extProjectID)
+		if err != nil {
+			return result, err
+		}
+		result.Polls++
+		result.LineItems = make([]*LineItemFeasibility, 0, len(res.List))
+		for _, f := range res.List {
+			l := &LineItemFeasibility{ExtLineItemID: f.ExtLineItemID, Feasibility: f.Feasibility, Quote: f.Quote}
+			switch l.Status() {
+			case FeasibilityStatusFailed:
+				l.Err = fmt.Errorf("line item %s: %w", l.ExtLineItemID, ErrFeasibilityFailed)
+			case FeasibilityStatusNotSupported:
+				l.Err = fmt.Errorf("line item %s: %w", l.ExtLineItemID, ErrFeasibilityNotSupported)
+			}
+			result.LineItems = append(result.LineItems, l)
+			if status, ok := previous[l.ExtLineItemID]; ok && status == l.Status() {
+				continue
+			}
+			previous[l.ExtLineItemID] = l.Status()
+			if err := o.report(ctx, l); err != nil {
+				return result, err
+			}
+		}
+		if result.Done() {
+			return result, nil
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, ctx.Err()
+		case <-timer.C:
+		}
+		interval = time.Duration(float64(interval) * o.Multiplier)
+		if interval > o.MaxInterval {
+			interval = o.MaxInterval
+		}
+	}
+}
+
+// WaitForFeasibility polls GetFeasibility until every line item of the project is READY, NOT_SUPPORTED or FAILED.
+func (c *Client) WaitForFeasibility(extProjectID string, opts *FeasibilityWaitOptions) (*FeasibilityResult, error) {
+	return c.WaitForFeasibilityWithContext(context.Background(), extProjectID, opts)
+}
+
+func (o *FeasibilityWaitOptions) report(ctx context.Context, l *LineItemFeasibility) error {
+	if o.Progress != nil {
+		o.Progress(l)
+	}
+	if o.Updates == nil {
+		return nil
+	}
+	select {
+	case o.Updates <- l:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
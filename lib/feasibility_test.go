@@ -0,0 +1,95 @@
+package samplify_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+func TestWaitForFeasibility(t *testing.T) {
+	var polls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		second := "PROCESSING"
+		if atomic.AddInt32(&polls, 1) >= 3 {
+			second = "FAILED"
+		}
+		fmt.Fprintf(w, `{"data": [
+			{"extLineItemId": "li1", "feasibility": {"status": "READY", "feasible": true}},
+			{"extLineItemId": "li2", "feasibility": {"status": "%s"}}
+		]}`, second)
+	}))
+	defer ts.Close()
+	client := samplify.NewClient("", "", "", &samplify.ClientOptions{APIBaseURL: ts.URL, AuthURL: ts.URL})
+	client.Auth = getAuth()
+
+	var progress []string
+	updates := make(chan *samplify.LineItemFeasibility, 10)
+	res, err := client.WaitForFeasibility("prj", &samplify.FeasibilityWaitOptions{
+		InitialInterval: time.Millisecond,
+		MaxInterval:     2 * time.Millisecond,
+		Progress: func(l *samplify.LineItemFeasibility) {
+			progress = append(progress, l.ExtLineItemID+":"+string(l.Status()))
+		},
+		Updates: updates,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Polls != 3 || !res.Done() || res.Feasible() {
+		t.Errorf("unexpected result %+v", res)
+	}
+	expected := []string{"li1:READY", "li2:PROCESSING", "li2:FAILED"}
+	if fmt.Sprint(progress) != fmt.Sprint(expected) || len(updates) != len(expected) {
+		t.Errorf("got progress %v, want %v", progress, expected)
+	}
+	errs := res.Errors()
+	if len(errs) != 1 || !errors.Is(errs[0], samplify.ErrFeasibilityFailed) {
+		t.Errorf("got errors %v, want a single ErrFeasibilityFailed", errs)
+	}
+
+	atomic.StoreInt32(&polls, -100)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	res, err = client.WaitForFeasibilityWithContext(ctx, "prj", &samplify.FeasibilityWaitOptions{InitialInterval: time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) || res.Done() || res.Polls == 0 {
+		t.Errorf("expected the wait to time out with a partial result, got %v, %+v", err, res)
+	}
+}
+
+func TestWaitForFeasibilityPages(t *testing.T) {
+	const count = 1500
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RawQuery)
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		var items []string
+		for i := offset; i < count && i < offset+limit; i++ {
+			items = append(items, fmt.Sprintf(`{"extLineItemId": "li%d", "feasibility": {"status": "READY", "feasible": true}}`, i))
+		}
+		fmt.Fprintf(w, `{"data": [%s]}`, strings.Join(items, ","))
+	}))
+	defer ts.Close()
+	client := samplify.NewClient("", "", "", &samplify.ClientOptions{APIBaseURL: ts.URL, AuthURL: ts.URL})
+	client.Auth = getAuth()
+
+	res, err := client.WaitForFeasibility("prj", &samplify.FeasibilityWaitOptions{InitialInterval: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.LineItems) != count || res.Polls != 1 || !res.Feasible() {
+		t.Errorf("got %d line items in %d polls, want %d in 1", len(res.LineItems), res.Polls, count)
+	}
+	if len(requests) != 2 || requests[1] != "offset=1000&limit=1000" {
+		t.Errorf("got requests %v, want 2 with offset=1000&limit=1000 last", requests)
+	}
+}
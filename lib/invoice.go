@@ -0,0 +1,46 @@
+package samplify
+
+// The invoice summary fields are not described in the API reference, which only documents the endpoint. The types
+// below follow the responses returned by /projects/invoices/summary so far and are provisional: fields may be renamed
+// or added once the shape is documented.
+
+// InvoiceSummary ... Billed amounts for an invoiced project (provisional, see above)
+type InvoiceSummary struct {
+	ExtProjectID string             `json:"extProjectId"`
+	Title        string             `json:"title"`
+	JobNumber    string             `json:"jobNumber"`
+	Currency     string             `json:"currency"`
+	TotalCost    float64            `json:"totalCost"`
+	Billing      *Billing           `json:"billing"`
+	LineItems    []*InvoiceLineItem `json:"lineItems"`
+}
+
+// InvoiceLineItem ... Billed amounts for a line item of an invoiced project (provisional)
+type InvoiceLineItem struct {
+	ExtLineItemID string                 `json:"extLineItemId"`
+	Title         string                 `json:"title"`
+	Currency      string                 `json:"currency"`
+	CostPerUnit   float64                `json:"costPerUnit"`
+	Completes     int64                  `json:"completes"`
+	TotalCost     float64                `json:"totalCost"`
+	DetailedCost  []*InvoiceDetailedCost `json:"detailedCost,omitempty"`
+}
+
+// InvoiceDetailedCost ... Billed amount of a single cost type of a line item (provisional)
+type InvoiceDetailedCost struct {
+	Title       string   `json:"title"`
+	Type        CostType `json:"type"`
+	CostPerUnit float64  `json:"costPerUnit"`
+	Units       int64    `json:"units"`
+	TotalCost   float64  `json:"totalCost"`
+}
+
+// LineItem returns the invoiced line item with the given id, or nil.
+func (s *InvoiceSummary) LineItem(extLineItemID string) *InvoiceLineItem {
+	for _, l := range s.LineItems {
+		if l.ExtLineItemID == extLineItemID {
+			return l
+		}
+	}
+	return nil
+}
@@ -0,0 +1,361 @@
+package samplify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// LifecycleStep is a step of the Lifecycle orchestrator.
+type LifecycleStep string
+
+// LifecycleStep values, in the order they are executed
+const (
+	LifecycleStepCreate      LifecycleStep = "create"
+	LifecycleStepFeasibility LifecycleStep = "feasibility"
+	LifecycleStepBuy         LifecycleStep = "buy"
+	LifecycleStepLaunch      LifecycleStep = "launch"
+)
+
+var lifecycleSteps = []LifecycleStep{LifecycleStepCreate, LifecycleStepFeasibility, LifecycleStepBuy, LifecycleStepLaunch}
+
+// DefaultLifecyclePollInterval is the delay between two checks of the state of a line item waiting to be launched.
+const DefaultLifecyclePollInterval = 30 * time.Second
+
+// Errors reported by the Lifecycle orchestrator
+var (
+	ErrLineItemNotFeasible     = errors.New("line item is not feasible")
+	ErrLineItemNotBuyable      = errors.New("line item cannot be bought")
+	ErrLineItemRejected        = errors.New("line item was rejected")
+	ErrLineItemNotFound        = errors.New("line item not found in the project")
+	ErrUnexpectedLineItemState = errors.New("unexpected line item state")
+)
+
+// LifecycleError tells which step, and which line item if any, made the lifecycle fail.
+type LifecycleError struct {
+	Step          LifecycleStep
+	ExtLineItemID string
+	Err           error
+}
+
+func (e *LifecycleError) Error() string {
+	if len(e.ExtLineItemID) == 0 {
+		return fmt.Sprintf("%s: %v", e.Step, e.Err)
+	}
+	return fmt.Sprintf("%s: line item %s: %v", e.Step, e.ExtLineItemID, e.Err)
+}
+
+func (e *LifecycleError) Unwrap() error {
+	return e.Err
+}
+
+// LifecycleCheckpoint records the progress of a lifecycle so that it can be resumed after a failure.
+type LifecycleCheckpoint struct {
+	ExtProjectID string          `json:"extProjectId"`
+	Completed    []LifecycleStep `json:"completed"`
+	Launched     []string        `json:"launched"`
+}
+
+func (cp *LifecycleCheckpoint) isCompleted(step LifecycleStep) bool {
+	for _, s := range cp.Completed {
+		if s == step {
+			return true
+		}
+	}
+	return false
+}
+
+func (cp *LifecycleCheckpoint) isLaunched(extLineItemID string) bool {
+	for _, id := range cp.Launched {
+		if id == extLineItemID {
+			return true
+		}
+	}
+	return false
+}
+
+// Lifecycle takes a project from creation to having every line item launched: CreateProject, feasibility,
+// BuyProject, then waiting for each line item to be approved and launched.
+type Lifecycle struct {
+	Client  *Client
+	Project *CreateProjectCriteria
+	// Buy overrides the survey URLs used to buy the line items. By default they are taken from Project.
+	Buy []*BuyProjectCriteria
+	// Feasibility configures the wait for the feasibility of the line items.
+	Feasibility *FeasibilityWaitOptions
+	// PollInterval is the delay between two checks of the state of a line item waiting to be launched.
+	PollInterval time.Duration
+	// DryRun checks the preconditions and reports the actions through Log without changing anything.
+	DryRun bool
+	// Checkpoint holds the progress of a previous run. It is updated as the steps complete.
+	Checkpoint *LifecycleCheckpoint
+	// OnCheckpoint is called every time the checkpoint changes, e.g. to persist it.
+	OnCheckpoint func(*LifecycleCheckpoint) error
+	// Log is called for every action taken, or that would be taken in a dry run.
+	Log func(step LifecycleStep, extLineItemID, message string)
+}
+
+// NewLifecycle returns a lifecycle for the project, with the default options.
+func NewLifecycle(c *Client, project *CreateProjectCriteria) *Lifecycle {
+	return &Lifecycle{Client: c, Project: project}
+}
+
+// RunWithContext executes the steps that are not completed in the checkpoint. It returns a *LifecycleError if a step
+// fails.
+func (l *Lifecycle) RunWithContext(ctx context.Context) error {
+	if l.Project == nil {
+		return ErrRequiredFieldEmpty
+	}
+	if l.Checkpoint == nil {
+		l.Checkpoint = &LifecycleCheckpoint{ExtProjectID: l.Project.ExtProjectID}
+	}
+	run := map[LifecycleStep]func(context.Context) error{
+		LifecycleStepCreate:      l.create,
+		LifecycleStepFeasibility: l.feasibility,
+		LifecycleStepBuy:         l.buy,
+		LifecycleStepLaunch:      l.launch,
+	}
+	for _, step := range lifecycleSteps {
+		if l.Checkpoint.isCompleted(step) {
+			continue
+		}
+		if err := run[step](ctx); err != nil {
+			if _, ok := err.(*LifecycleError); !ok {
+				err = &LifecycleError{Step: step, Err: err}
+			}
+			return err
+		}
+		if l.DryRun {
+			continue
+		}
+		l.Checkpoint.Completed = append(l.Checkpoint.Completed, step)
+		if err := l.checkpoint(); err != nil {
+			return &LifecycleError{Step: step, Err: err}
+		}
+	}
+	return nil
+}
+
+// Run executes the steps that are not completed in the checkpoint.
+func (l *Lifecycle) Run() error {
+	return l.RunWithContext(context.Background())
+}
+
+func (l *Lifecycle) log(step LifecycleStep, extLineItemID, format string, args ...interface{}) {
+	if l.Log != nil {
+		l.Log(step, extLineItemID, fmt.Sprintf(format, args...))
+	}
+}
+
+func (l *Lifecycle) checkpoint() error {
+	if l.OnCheckpoint == nil {
+		return nil
+	}
+	return l.OnCheckpoint(l.Checkpoint)
+}
+
+// getProject returns nil if the project does not exist.
+func (l *Lifecycle) getProject(ctx context.Context) (*Project, error) {
+	res, err := l.Client.GetProjectByWithContext(ctx, l.Project.ExtProjectID)
+	if errResp, ok := err.(*ErrorResponse); ok && errResp.HTTPCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res.Project, nil
+}
+
+func (l *Lifecycle) create(ctx context.Context) error {
+	project, err := l.getProject(ctx)
+	if err != nil {
+		return err
+	}
+	if project != nil {
+		l.log(LifecycleStepCreate, "", "project %s already exists", l.Project.ExtProjectID)
+		return nil
+	}
+	if l.DryRun {
+		if err := Validate(l.Project); err != nil {
+			return err
+		}
+		l.log(LifecycleStepCreate, "", "would create project %s", l.Project.ExtProjectID)
+		return nil
+	}
+	if _, err := l.Client.CreateProjectWithContext(ctx, l.Project); err != nil {
+		return err
+	}
+	l.log(LifecycleStepCreate, "", "created project %s", l.Project.ExtProjectID)
+	return nil
+}
+
+func (l *Lifecycle) feasibility(ctx context.Context) error {
+	if l.DryRun {
+		if project, err := l.getProject(ctx); err != nil || project == nil {
+			l.log(LifecycleStepFeasibility, "", "would wait for the feasibility of the line items")
+			return err
+		}
+	}
+	res, err := l.Client.WaitForFeasibilityWithContext(ctx, l.Project.ExtProjectID, l.Feasibility)
+	if err != nil {
+		return err
+	}
+	for _, f := range res.LineItems {
+		if f.Err != nil {
+			return &LifecycleError{Step: LifecycleStepFeasibility, ExtLineItemID: f.ExtLineItemID, Err: f.Err}
+		}
+		if !f.Feasibility.Feasible {
+			return &LifecycleError{Step: LifecycleStepFeasibility, ExtLineItemID: f.ExtLineItemID, Err: ErrLineItemNotFeasible}
+		}
+		l.log(LifecycleStepFeasibility, f.ExtLineItemID, "feasible, %d available at %.2f %s per interview",
+			f.Feasibility.TotalCount, f.Feasibility.CostPerInterview, f.Feasibility.Currency)
+	}
+	return nil
+}
+
+func (l *Lifecycle) buyCriteria(extLineItemID string) (*BuyProjectCriteria, error) {
+	for _, b := range l.Buy {
+		if b.ExtLineItemID == extLineItemID {
+			return b, nil
+		}
+	}
+	for _, li := range l.Project.LineItems {
+		if li.ExtLineItemID != extLineItemID {
+			continue
+		}
+		if li.SurveyURL == nil || li.SurveyTestURL == nil {
+			return nil, ErrRequiredFieldEmpty
+		}
+		return &BuyProjectCriteria{
+			ExtLineItemID:      extLineItemID,
+			SurveyURL:          *li.SurveyURL,
+			SurveyTestURL:      *li.SurveyTestURL,
+			SurveyTestingNotes: li.SurveyTestingNotes,
+		}, nil
+	}
+	return nil, ErrLineItemNotFound
+}
+
+func (l *Lifecycle) buy(ctx context.Context) error {
+	project, err := l.getProject(ctx)
+	if err != nil {
+		return err
+	}
+	states := make(map[string]*LineItem)
+	if project != nil {
+		for _, li := range project.LineItems {
+			states[li.ExtLineItemID] = li
+		}
+	}
+	var buy []*BuyProjectCriteria
+	for _, li := range l.Project.LineItems {
+		current, ok := states[li.ExtLineItemID]
+		switch {
+		case !ok && project != nil:
+			return &LifecycleError{Step: LifecycleStepBuy, ExtLineItemID: li.ExtLineItemID, Err: ErrLineItemNotFound}
+		case ok && !current.IsBuyable():
+			if isFinalState(current.State) {
+				return &LifecycleError{Step: LifecycleStepBuy, ExtLineItemID: li.ExtLineItemID,
+					Err: fmt.Errorf("%w: %s", ErrLineItemNotBuyable, current.State)}
+			}
+			l.log(LifecycleStepBuy, li.ExtLineItemID, "already bought, %s", current.State)
+			continue
+		}
+		criteria, err := l.buyCriteria(li.ExtLineItemID)
+		if err != nil {
+			return &LifecycleError{Step: LifecycleStepBuy, ExtLineItemID: li.ExtLineItemID, Err: err}
+		}
+		buy = append(buy, criteria)
+	}
+	if len(buy) == 0 {
+		return nil
+	}
+	if l.DryRun {
+		if err := Validate(buy); err != nil {
+			return err
+		}
+		for _, b := range buy {
+			l.log(LifecycleStepBuy, b.ExtLineItemID, "would buy")
+		}
+		return nil
+	}
+	res, err := l.Client.BuyProjectWithContext(ctx, l.Project.ExtProjectID, buy)
+	if err != nil {
+		return err
+	}
+	for _, b := range res.List {
+		l.log(LifecycleStepBuy, b.ExtLineItemID, "bought, %s", b.State)
+	}
+	return nil
+}
+
+func (l *Lifecycle) launch(ctx context.Context) error {
+	for _, li := range l.Project.LineItems {
+		if l.Checkpoint.isLaunched(li.ExtLineItemID) {
+			continue
+		}
+		if l.DryRun {
+			l.log(LifecycleStepLaunch, li.ExtLineItemID, "would wait for approval and launch")
+			continue
+		}
+		if err := l.launchLineItem(ctx, li.ExtLineItemID); err != nil {
+			return &LifecycleError{Step: LifecycleStepLaunch, ExtLineItemID: li.ExtLineItemID, Err: err}
+		}
+		l.Checkpoint.Launched = append(l.Checkpoint.Launched, li.ExtLineItemID)
+		if err := l.checkpoint(); err != nil {
+			return &LifecycleError{Step: LifecycleStepLaunch, ExtLineItemID: li.ExtLineItemID, Err: err}
+		}
+	}
+	return nil
+}
+
+// launchLineItem waits for the line item to be approved, launches it if it is paused, and returns once it is
+// LAUNCHED.
+func (l *Lifecycle) launchLineItem(ctx context.Context, extLineItemID string) error {
+	interval := l.PollInterval
+	if interval <= 0 {
+		interval = DefaultLifecyclePollInterval
+	}
+	var last State
+	for {
+		res, err := l.Client.GetLineItemByWithContext(ctx, l.Project.ExtProjectID, extLineItemID)
+		if err != nil {
+			return err
+		}
+		if res.Item == nil {
+			return ErrLineItemNotFound
+		}
+		state := res.Item.State
+		if state != last {
+			l.log(LifecycleStepLaunch, extLineItemID, "%s", state)
+			last = state
+		}
+		switch state {
+		case StateLaunched:
+			return nil
+		case StateAwaitingApproval, StateAwaitingClientApproval, StateQAApproved:
+		case StatePaused, StateAwaitingApprovalPaused:
+			if _, err := l.Client.LaunchLineItemWithContext(ctx, l.Project.ExtProjectID, extLineItemID); err != nil {
+				return err
+			}
+		case StateRejected, StateRejectedPaused:
+			return fmt.Errorf("%w: %s", ErrLineItemRejected, res.Item.StateReason)
+		default:
+			return fmt.Errorf("%w: %s", ErrUnexpectedLineItemState, state)
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func isFinalState(s State) bool {
+	return s == StateClosed || s == StateCancelled || s == StateCompleted || s == StateInvoiced
+}
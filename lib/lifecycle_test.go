@@ -0,0 +1,116 @@
+package samplify_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+// lifecycleServer simulates a project going through creation, buying, approval and launch.
+type lifecycleServer struct {
+	mu       sync.Mutex
+	state    samplify.State
+	feasible bool
+	writes   []string
+}
+
+func (s *lifecycleServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.Method == "POST" {
+		s.writes = append(s.writes, r.URL.Path)
+	}
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/projects/project001":
+		if len(s.state) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"httpCode": 404}`))
+			return
+		}
+		fmt.Fprintf(w, `{"data": {"extProjectId": "project001", "lineItems": [{"extLineItemId": "lineItem001", "state": "%s"}]}}`, s.state)
+	case r.Method == "POST" && r.URL.Path == "/projects":
+		s.state = samplify.StateProvisioned
+		w.Write([]byte(`{"data": {"extProjectId": "project001"}}`))
+	case r.URL.Path == "/projects/project001/feasibility":
+		fmt.Fprintf(w, `{"data": [{"extLineItemId": "lineItem001", "feasibility": {"status": "READY", "feasible": %t}}]}`, s.feasible)
+	case r.URL.Path == "/projects/project001/buy":
+		s.state = samplify.StateAwaitingApproval
+		w.Write([]byte(`{"data": [{"extLineItemId": "lineItem001", "state": "AWAITING_APPROVAL"}]}`))
+	case r.URL.Path == "/projects/project001/lineItems/lineItem001":
+		fmt.Fprintf(w, `{"data": {"extLineItemId": "lineItem001", "state": "%s"}}`, s.state)
+		if s.state == samplify.StateAwaitingApproval {
+			s.state = samplify.StatePaused
+		}
+	case r.URL.Path == "/projects/project001/lineItems/lineItem001/launch":
+		s.state = samplify.StateLaunched
+		w.Write([]byte(`{"data": {"extLineItemId": "lineItem001", "state": "LAUNCHED"}}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestLifecycle(t *testing.T) {
+	tables := []struct {
+		name       string
+		feasible   bool
+		dryRun     bool
+		checkpoint *samplify.LifecycleCheckpoint
+		writes     int
+		completed  int
+		step       samplify.LifecycleStep
+	}{
+		{"Case 1: full run", true, false, nil, 3, 4, ""},
+		{"Case 2: dry run changes nothing", true, true, nil, 0, 0, ""},
+		{"Case 3: not feasible", false, false, nil, 1, 1, samplify.LifecycleStepFeasibility},
+		{"Case 4: completed checkpoint", true, false, &samplify.LifecycleCheckpoint{
+			Completed: []samplify.LifecycleStep{
+				samplify.LifecycleStepCreate, samplify.LifecycleStepFeasibility, samplify.LifecycleStepBuy, samplify.LifecycleStepLaunch,
+			},
+		}, 0, 4, ""},
+	}
+
+	for _, table := range tables {
+		s := &lifecycleServer{feasible: table.feasible}
+		ts := httptest.NewServer(s)
+		client := samplify.NewClient("", "", "", &samplify.ClientOptions{APIBaseURL: ts.URL, AuthURL: ts.URL})
+		client.Auth = getAuth()
+
+		checkpoints := 0
+		l := samplify.NewLifecycle(client, getProjectCriteria())
+		l.Feasibility = &samplify.FeasibilityWaitOptions{InitialInterval: time.Millisecond}
+		l.PollInterval = time.Millisecond
+		l.DryRun = table.dryRun
+		l.Checkpoint = table.checkpoint
+		l.OnCheckpoint = func(*samplify.LifecycleCheckpoint) error {
+			checkpoints++
+			return nil
+		}
+		err := l.Run()
+		ts.Close()
+
+		if len(table.step) == 0 && err != nil {
+			t.Errorf("%s: %v", table.name, err)
+		}
+		if len(table.step) > 0 {
+			lerr, ok := err.(*samplify.LifecycleError)
+			if !ok || lerr.Step != table.step || lerr.ExtLineItemID != "lineItem001" || !errors.Is(err, samplify.ErrLineItemNotFeasible) {
+				t.Errorf("%s: got error %v, want a failure of line item lineItem001 at step %s", table.name, err, table.step)
+			}
+		}
+		if len(s.writes) != table.writes {
+			t.Errorf("%s: got writes %v, want %d", table.name, s.writes, table.writes)
+		}
+		if len(l.Checkpoint.Completed) != table.completed {
+			t.Errorf("%s: got completed steps %v, want %d", table.name, l.Checkpoint.Completed, table.completed)
+		}
+		if table.checkpoint == nil && !table.dryRun && table.feasible && (checkpoints != 5 || s.state != samplify.StateLaunched) {
+			t.Errorf("%s: got %d checkpoints and state %s, want 5 and LAUNCHED", table.name, checkpoints, s.state)
+		}
+	}
+}
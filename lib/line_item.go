@@ -113,11 +113,6 @@ type LineItemHeader struct {
 	LaunchedAt    *CustomTime `json:"launchedAt"`
 }
 
-type Schedule struct {
-	StartTime string `json:"startTime"`
-	EndTime   string `json:"endTime"`
-}
-
 // LineItem ...
 type LineItem struct {
 	LineItemHeader
@@ -147,9 +142,7 @@ func (l *LineItem) IsUpdateable() bool {
 
 // IsBuyable returns true if the lineitem can be bought or not
 func (l *LineItem) IsBuyable() bool {
-	return l.State == StateProvisioned ||
-		l.State == StateRejected ||
-		l.State == StateRejectedPaused
+	return CanApply(l.State, ActionBuy)
 }
 
 // IsRebalanceable returns false if the line item cannot be updated.
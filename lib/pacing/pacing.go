@@ -0,0 +1,296 @@
+// Package pacing pauses and relaunches the quota cells of a line item to keep its sample balanced over the field
+// period.
+package pacing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+	"github.com/morningconsult/go-samplifyapi-client/lib/quota"
+)
+
+// Defaults of a Controller
+const (
+	DefaultInterval   = 15 * time.Minute
+	DefaultPauseAbove = 0.1
+)
+
+// Errors returned by the Controller
+var (
+	ErrFieldingOver      = errors.New("the line item is no longer in field")
+	ErrNoFieldPeriod     = errors.New("the line item has no field schedule and has not been launched")
+	ErrInvalidThresholds = errors.New("ResumeBelow must be lower than PauseAbove")
+)
+
+// Reason explains a decision of the controller.
+type Reason string
+
+// Reason values
+const (
+	ReasonTargetReached Reason = "TARGET_REACHED"
+	ReasonAhead         Reason = "AHEAD_OF_PACE"
+	ReasonBackOnPace    Reason = "BACK_ON_PACE"
+)
+
+// Curve returns the share of the target expected after a share of the field period, both between 0 and 1.
+type Curve func(elapsed float64) float64
+
+// Linear expects the completes to come evenly over the field period.
+func Linear(elapsed float64) float64 {
+	return elapsed
+}
+
+// Decision is a change of status of a quota cell, made or, in a dry run, only planned. Err is set when the change
+// failed.
+type Decision struct {
+	Time          time.Time                `json:"time"`
+	ExtProjectID  string                   `json:"extProjectId"`
+	ExtLineItemID string                   `json:"extLineItemId"`
+	QuotaCellID   string                   `json:"quotaCellId"`
+	Completes     int64                    `json:"completes"`
+	Target        uint32                   `json:"target"`
+	Expected      float64                  `json:"expected"`
+	From          samplify.QCellStatusType `json:"from"`
+	Action        samplify.Action          `json:"action"`
+	Reason        Reason                   `json:"reason"`
+	DryRun        bool                     `json:"dryRun"`
+	Err           error                    `json:"-"`
+}
+
+// Controller compares the completes of every quota cell of a launched line item with its target and with the
+// completes expected at this point of the field period. A launched cell is paused when it reaches its target or gets
+// more than PauseAbove ahead of the expected completes; a paused cell below its target is relaunched once it is no more
+// than ResumeBelow ahead. The gap between the two thresholds keeps cells from flapping.
+type Controller struct {
+	Client        *samplify.Client
+	ExtProjectID  string
+	ExtLineItemID string
+	// Interval is the delay between two steps of Run.
+	Interval time.Duration
+	// Curve gives the completes expected over the field period. It defaults to Linear.
+	Curve Curve
+	// PauseAbove is the share of the expected completes a launched cell can be ahead by before it is paused. It
+	// defaults to DefaultPauseAbove.
+	PauseAbove float64
+	// ResumeBelow is the share of the expected completes a paused cell can be ahead by and still be relaunched.
+	ResumeBelow float64
+	// MinCompletes keeps cells with fewer completes from being paused for being ahead, e.g. early in the field period.
+	MinCompletes int64
+	// DryRun reports the decisions without changing the cells.
+	DryRun bool
+	// OnDecision is called for every decision.
+	OnDecision func(*Decision)
+	// OnError is called when Run fails to step.
+	OnError func(error)
+	// Audit receives every decision as a line of JSON.
+	Audit io.Writer
+}
+
+// NewController returns a controller of the line item, with the default options.
+func NewController(c *samplify.Client, extProjectID, extLineItemID string) *Controller {
+	return &Controller{Client: c, ExtProjectID: extProjectID, ExtLineItemID: extLineItemID}
+}
+
+// RunWithContext steps every Interval until the line item leaves the field or the context is done. Errors of a step
+// are reported to OnError and do not stop the controller.
+func (c *Controller) RunWithContext(ctx context.Context) error {
+	interval := c.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	for {
+		_, err := c.StepWithContext(ctx)
+		switch {
+		case errors.Is(err, ErrFieldingOver):
+			return nil
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case errors.Is(err, ErrInvalidThresholds):
+			return err
+		case err != nil && c.OnError != nil:
+			c.OnError(err)
+		}
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Run steps every Interval until the line item leaves the field.
+func (c *Controller) Run() error {
+	return c.RunWithContext(context.Background())
+}
+
+// StepWithContext reads the line item and its detailed report once, and pauses or relaunches the cells that need it.
+// Failures to change a cell are set in its decision. A line item that is not launched is left alone, and
+// ErrFieldingOver is returned once it is completed, closed, cancelled or invoiced.
+func (c *Controller) StepWithContext(ctx context.Context) ([]*Decision, error) {
+	if c.ResumeBelow >= c.pauseAbove() {
+		return nil, ErrInvalidThresholds
+	}
+	res, err := c.Client.GetLineItemByWithContext(ctx, c.ExtProjectID, c.ExtLineItemID)
+	if err != nil {
+		return nil, err
+	}
+	l := res.Item
+	if l == nil {
+		return nil, samplify.ErrRequiredFieldEmpty
+	}
+	switch l.State {
+	case samplify.StateCompleted, samplify.StateClosed, samplify.StateCancelled, samplify.StateInvoiced:
+		return nil, fmt.Errorf("%w: %s", ErrFieldingOver, l.State)
+	case samplify.StateLaunched:
+	default:
+		return nil, nil
+	}
+
+	now := time.Now()
+	start, end, err := fieldPeriod(l)
+	if err != nil {
+		return nil, err
+	}
+	elapsed := 1.0
+	if end.After(start) {
+		elapsed = float64(now.Sub(start)) / float64(end.Sub(start))
+	}
+	curve := c.Curve
+	if curve == nil {
+		curve = Linear
+	}
+	share := clamp(curve(clamp(elapsed)))
+
+	target, err := quota.Target(l.Targets)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := quota.ToCounts(l.QuotaPlan, target)
+	if err != nil || plan == nil {
+		return nil, err
+	}
+	report, err := c.Client.GetDetailedLineItemReportWithContext(ctx, c.ExtProjectID, c.ExtLineItemID)
+	if err != nil {
+		return nil, err
+	}
+	completes := make(map[string]int64)
+	for _, g := range report.Report.QuotaGroups {
+		for _, cell := range g.QuotaCells {
+			completes[cell.QuotaCellID] = cell.Stats.Completes
+		}
+	}
+
+	var decisions []*Decision
+	for _, g := range plan.QuotaGroups {
+		for _, cell := range g.QuotaCells {
+			if cell.QuotaCellID == nil || cell.Count == nil {
+				continue
+			}
+			d := &Decision{
+				Time:          now,
+				ExtProjectID:  c.ExtProjectID,
+				ExtLineItemID: c.ExtLineItemID,
+				QuotaCellID:   *cell.QuotaCellID,
+				Completes:     completes[*cell.QuotaCellID],
+				Target:        *cell.Count,
+				Expected:      float64(*cell.Count) * share,
+				From:          samplify.QCellStatusTypeLaunch,
+				DryRun:        c.DryRun,
+			}
+			if cell.Status != nil {
+				d.From = *cell.Status
+			}
+			if !c.decide(d) {
+				continue
+			}
+			if !c.DryRun {
+				_, d.Err = c.Client.SetQuotaCellStatusWithContext(ctx, c.ExtProjectID, c.ExtLineItemID, d.QuotaCellID, d.Action)
+			}
+			decisions = append(decisions, d)
+			if err := c.record(d); err != nil {
+				return decisions, err
+			}
+		}
+	}
+	return decisions, nil
+}
+
+// Step reads the line item and its detailed report once, and pauses or relaunches the cells that need it.
+func (c *Controller) Step() ([]*Decision, error) {
+	return c.StepWithContext(context.Background())
+}
+
+// decide sets the action and reason of the decision, and tells whether the cell must change.
+func (c *Controller) decide(d *Decision) bool {
+	reached := d.Completes >= int64(d.Target)
+	switch d.From {
+	case samplify.QCellStatusTypePause:
+		if !reached && float64(d.Completes) <= d.Expected*(1+c.ResumeBelow) {
+			d.Action, d.Reason = samplify.ActionLaunched, ReasonBackOnPace
+			return true
+		}
+	default:
+		if reached {
+			d.Action, d.Reason = samplify.ActionPaused, ReasonTargetReached
+			return true
+		}
+		if d.Completes >= c.MinCompletes && float64(d.Completes) > d.Expected*(1+c.pauseAbove()) {
+			d.Action, d.Reason = samplify.ActionPaused, ReasonAhead
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Controller) pauseAbove() float64 {
+	if c.PauseAbove <= 0 {
+		return DefaultPauseAbove
+	}
+	return c.PauseAbove
+}
+
+func (c *Controller) record(d *Decision) error {
+	if c.OnDecision != nil {
+		c.OnDecision(d)
+	}
+	if c.Audit == nil {
+		return nil
+	}
+	entry := struct {
+		*Decision
+		Error string `json:"error,omitempty"`
+	}{Decision: d}
+	if d.Err != nil {
+		entry.Error = d.Err.Error()
+	}
+	return json.NewEncoder(c.Audit).Encode(entry)
+}
+
+// fieldPeriod returns the field schedule of the line item, or else the days in field from its launch.
+func fieldPeriod(l *samplify.LineItem) (time.Time, time.Time, error) {
+	if s := l.FieldSchedule; s != nil && !s.StartTime.IsZero() && !s.EndTime.IsZero() {
+		return s.StartTime, s.EndTime, nil
+	}
+	if l.LaunchedAt == nil || !l.LaunchedAt.IsSet() {
+		return time.Time{}, time.Time{}, ErrNoFieldPeriod
+	}
+	start := l.LaunchedAt.Time
+	return start, start.AddDate(0, 0, int(l.DaysInField)), nil
+}
+
+func clamp(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	if x > 1 {
+		return 1
+	}
+	return x
+}
@@ -0,0 +1,119 @@
+package pacing_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+	"github.com/morningconsult/go-samplifyapi-client/lib/pacing"
+)
+
+func TestController(t *testing.T) {
+	start := time.Now().Add(-5 * 24 * time.Hour).UTC().Format(time.RFC3339)
+	end := time.Now().Add(5 * 24 * time.Hour).UTC().Format(time.RFC3339)
+	// Halfway through the field period, every cell of 100 completes is expected to have 50.
+	cells := []struct {
+		id        string
+		status    string
+		completes int
+	}{
+		{"c1", "LAUNCHED", 70},  // ahead of pace
+		{"c2", "PAUSED", 40},    // back on pace
+		{"c3", "LAUNCHED", 100}, // target reached
+		{"c4", "LAUNCHED", 52},  // within the tolerance
+		{"c5", "PAUSED", 53},    // still ahead
+	}
+	var planCells, reportCells []string
+	for _, c := range cells {
+		planCells = append(planCells, fmt.Sprintf(`{"quotaCellId": %q, "count": 100, "status": %q,
+			"quotaNodes": [{"attributeId": "11", "options": [%q]}]}`, c.id, c.status, c.id))
+		reportCells = append(reportCells, fmt.Sprintf(`{"quotaCellId": %q, "stats": {"completes": %d}}`, c.id, c.completes))
+	}
+	state := "LAUNCHED"
+
+	var mu sync.Mutex
+	var calls []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/projects/p1/lineItems/l1":
+			fmt.Fprintf(w, `{"data": {"extLineItemId": "l1", "state": %q,
+				"fieldSchedule": {"startTime": %q, "endTime": %q},
+				"targets": [{"count": 500, "type": "COMPLETE"}],
+				"quotaPlan": {"quotaGroups": [{"name": "cells", "quotaCells": [%s]}]}}}`,
+				state, start, end, strings.Join(planCells, ","))
+		case r.Method == "GET" && r.URL.Path == "/projects/p1/lineItems/l1/detailedReport":
+			fmt.Fprintf(w, `{"data": {"extLineItemId": "l1", "quotaGroups": [{"quotaGroupId": "g1", "quotaCells": [%s]}]}}`,
+				strings.Join(reportCells, ","))
+		case r.Method == "POST" && strings.HasPrefix(r.URL.Path, "/projects/p1/lineItems/l1/quotaCells/"):
+			calls = append(calls, strings.TrimPrefix(r.URL.Path, "/projects/p1/lineItems/l1/quotaCells/"))
+			fmt.Fprint(w, `{"data": {}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	now := time.Now()
+	client := samplify.NewClient("", "", "", &samplify.ClientOptions{APIBaseURL: ts.URL, AuthURL: ts.URL})
+	client.Auth = samplify.TokenResponse{AccessToken: "test", Acquired: &now, ExpiresIn: 1800}
+
+	c := pacing.NewController(client, "p1", "l1")
+	c.DryRun = true
+	audit := &bytes.Buffer{}
+	c.Audit = audit
+	decisions, err := c.Step()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, d := range decisions {
+		got = append(got, fmt.Sprintf("%s %s %s %v", d.QuotaCellID, d.Action, d.Reason, d.DryRun))
+	}
+	expected := []string{"c1 pause AHEAD_OF_PACE true", "c2 launch BACK_ON_PACE true", "c3 pause TARGET_REACHED true"}
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("got decisions %v, want %v", got, expected)
+	}
+	if len(calls) != 0 {
+		t.Errorf("dry run changed cells: %v", calls)
+	}
+	lines := strings.Split(strings.TrimSpace(audit.String()), "\n")
+	var entry map[string]interface{}
+	if len(lines) != 3 || json.Unmarshal([]byte(lines[0]), &entry) != nil || entry["quotaCellId"] != "c1" || entry["reason"] != "AHEAD_OF_PACE" {
+		t.Errorf("unexpected audit log:\n%s", audit.String())
+	}
+
+	c.DryRun, c.MinCompletes = false, 80
+	if decisions, err = c.Step(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(calls) != "[c2/launch c3/pause]" {
+		t.Errorf("got calls %v, want [c2/launch c3/pause]", calls)
+	}
+	for _, d := range decisions {
+		if d.Err != nil {
+			t.Errorf("cell %s: %v", d.QuotaCellID, d.Err)
+		}
+	}
+
+	c.PauseAbove, c.ResumeBelow = 0.1, 0.2
+	if _, err := c.Step(); err != pacing.ErrInvalidThresholds {
+		t.Errorf("got error %v, want ErrInvalidThresholds", err)
+	}
+	c.ResumeBelow = 0
+
+	mu.Lock()
+	state = "COMPLETED"
+	mu.Unlock()
+	c.Interval = time.Millisecond
+	if err := c.Run(); err != nil {
+		t.Errorf("Run: got error %v, want nil once the line item is completed", err)
+	}
+}
@@ -0,0 +1,121 @@
+package samplify
+
+import "context"
+
+// readPages calls read with increasing offsets, maxLimit items at a time, until a page is short or the total given by
+// the API is reached.
+func readPages(read func(options *QueryOptions) (n int, total int64, err error)) error {
+	options := &QueryOptions{Limit: maxLimit}
+	var count int64
+	for {
+		n, total, err := read(options)
+		if err != nil {
+			return err
+		}
+		count += int64(n)
+		if uint(n) < options.Limit || (total > 0 && count >= total) {
+			return nil
+		}
+		options.Offset += options.Limit
+	}
+}
+
+// AllSurveyTopicsWithContext reads every page of the survey topics.
+func (c *Client) AllSurveyTopicsWithContext(ctx context.Context) ([]*SurveyTopic, error) {
+	var res []*SurveyTopic
+	err := readPages(func(options *QueryOptions) (int, int64, error) {
+		page, err := c.GetSurveyTopicsWithContext(ctx, options)
+		if err != nil {
+			return 0, 0, err
+		}
+		res = append(res, page.List...)
+		return len(page.List), page.Meta.Total, nil
+	})
+	return res, err
+}
+
+// AllSurveyTopics reads every page of the survey topics.
+func (c *Client) AllSurveyTopics() ([]*SurveyTopic, error) {
+	return c.AllSurveyTopicsWithContext(context.Background())
+}
+
+// AllSourcesWithContext reads every page of the sample sources.
+func (c *Client) AllSourcesWithContext(ctx context.Context) ([]*SampleSource, error) {
+	var res []*SampleSource
+	err := readPages(func(options *QueryOptions) (int, int64, error) {
+		page, err := c.GetSourcesWithContext(ctx, options)
+		if err != nil {
+			return 0, 0, err
+		}
+		res = append(res, page.List...)
+		return len(page.List), page.Meta.Total, nil
+	})
+	return res, err
+}
+
+// AllSources reads every page of the sample sources.
+func (c *Client) AllSources() ([]*SampleSource, error) {
+	return c.AllSourcesWithContext(context.Background())
+}
+
+// AllCountriesWithContext reads every page of the countries.
+func (c *Client) AllCountriesWithContext(ctx context.Context) ([]*Country, error) {
+	var res []*Country
+	err := readPages(func(options *QueryOptions) (int, int64, error) {
+		page, err := c.GetCountriesWithContext(ctx, options)
+		if err != nil {
+			return 0, 0, err
+		}
+		res = append(res, page.List...)
+		return len(page.List), page.Meta.Total, nil
+	})
+	return res, err
+}
+
+// AllCountries reads every page of the countries.
+func (c *Client) AllCountries() ([]*Country, error) {
+	return c.AllCountriesWithContext(context.Background())
+}
+
+// AllAttributesWithContext reads every page of the attributes of a country and language.
+func (c *Client) AllAttributesWithContext(ctx context.Context, countryCode, languageCode string) ([]*Attribute, error) {
+	var res []*Attribute
+	err := readPages(func(options *QueryOptions) (int, int64, error) {
+		page, err := c.GetAttributesWithContext(ctx, countryCode, languageCode, options)
+		if err != nil {
+			return 0, 0, err
+		}
+		res = append(res, page.List...)
+		return len(page.List), page.Meta.Total, nil
+	})
+	return res, err
+}
+
+// AllAttributes reads every page of the attributes of a country and language.
+func (c *Client) AllAttributes(countryCode, languageCode string) ([]*Attribute, error) {
+	return c.AllAttributesWithContext(context.Background(), countryCode, languageCode)
+}
+
+// AllFeasibilityWithContext reads every page of the feasibility of a project's line items. The status is the one of
+// the last page.
+func (c *Client) AllFeasibilityWithContext(ctx context.Context, extProjectID string) (*GetFeasibilityResponse, error) {
+	res := &GetFeasibilityResponse{}
+	err := readPages(func(options *QueryOptions) (int, int64, error) {
+		page, err := c.GetFeasibilityWithContext(ctx, extProjectID, options)
+		if err != nil {
+			return 0, 0, err
+		}
+		res.List = append(res.List, page.List...)
+		res.ResponseStatus = page.ResponseStatus
+		return len(page.List), 0, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// AllFeasibility reads every page of the feasibility of a project's line items.
+func (c *Client) AllFeasibility(extProjectID string) (*GetFeasibilityResponse, error) {
+	return c.AllFeasibilityWithContext(context.Background(), extProjectID)
+}
@@ -0,0 +1,80 @@
+package samplify_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+// pagedServer serves count items by offset and limit, with the total in the meta when withTotal is set.
+func pagedServer(count int, withTotal bool, requests *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r.URL.RawQuery)
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+		if err != nil {
+			limit = 10
+		}
+		var items []string
+		for i := offset; i < count && i < offset+limit; i++ {
+			items = append(items, fmt.Sprintf(`{"id": "%d", "topic": "t%d", "countryISOCode": "US"}`, i, i))
+		}
+		total := 0
+		if withTotal {
+			total = count
+		}
+		fmt.Fprintf(w, `{"data": [%s], "meta": {"total": %d}}`, strings.Join(items, ","), total)
+	}))
+}
+
+func TestAllPages(t *testing.T) {
+	tests := []struct {
+		name      string
+		count     int
+		withTotal bool
+		requests  int
+	}{
+		{"single page", 15, false, 1},
+		{"several pages", 2500, false, 3},
+		{"exact pages", 2000, false, 3},
+		{"exact pages with total", 2000, true, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			ts := pagedServer(tt.count, tt.withTotal, &requests)
+			defer ts.Close()
+			now := time.Now()
+			client := samplify.NewClient("", "", "", &samplify.ClientOptions{APIBaseURL: ts.URL, AuthURL: ts.URL})
+			client.Auth = samplify.TokenResponse{AccessToken: "test", Acquired: &now, ExpiresIn: 1800}
+
+			attributes, err := client.AllAttributes("US", "en")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(attributes) != tt.count || attributes[tt.count-1].ID != strconv.Itoa(tt.count-1) {
+				t.Errorf("got %d attributes, want %d", len(attributes), tt.count)
+			}
+			if len(requests) != tt.requests {
+				t.Errorf("got requests %v, want %d", requests, tt.requests)
+			}
+			if len(requests) > 1 && requests[1] != "offset=1000&limit=1000" {
+				t.Errorf("got second request %q, want %q", requests[1], "offset=1000&limit=1000")
+			}
+			topics, err := client.AllSurveyTopics()
+			if err != nil || len(topics) != tt.count {
+				t.Errorf("got %d topics and error %v, want %d", len(topics), err, tt.count)
+			}
+			countries, err := client.AllCountries()
+			if err != nil || len(countries) != tt.count {
+				t.Errorf("got %d countries and error %v, want %d", len(countries), err, tt.count)
+			}
+		})
+	}
+}
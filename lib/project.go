@@ -203,6 +203,20 @@ type Reconcile struct {
 	Description string `json:"description"`
 }
 
+// ReconcileResult ... Outcome of processing a Request correction file
+type ReconcileResult struct {
+	Accepted int64                `json:"accepted"`
+	Rejected int64                `json:"rejected"`
+	Errors   []*ReconcileRowError `json:"errors"`
+}
+
+// ReconcileRowError ... Describes why a row of the Request correction file was rejected
+type ReconcileRowError struct {
+	Row          int64  `json:"row"`
+	RespondentID string `json:"respondentId"`
+	Message      string `json:"message"`
+}
+
 // SurveyTopic ... Represents Survey Topic for a project. Required to setup a project
 type SurveyTopic struct {
 	Topic       string `json:"topic"`
@@ -219,3 +233,11 @@ func (p *Project) IsUserSubscribed(email string) bool {
 
 	return false
 }
+
+// IsUpdateable returns false if the project can no longer be updated.
+func (p *Project) IsUpdateable() bool {
+	return p.State != StateClosed &&
+		p.State != StateCancelled &&
+		p.State != StateInvoiced &&
+		p.State != StateCompleted
+}
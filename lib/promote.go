@@ -0,0 +1,292 @@
+package samplify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// MappingKind is the kind of reference that PlanPromotion maps to the target environment.
+type MappingKind string
+
+// MappingKind values
+const (
+	MappingAttribute       MappingKind = "attribute"
+	MappingAttributeOption MappingKind = "attributeOption"
+	MappingSource          MappingKind = "source"
+	MappingSurveyTopic     MappingKind = "surveyTopic"
+)
+
+// MappingIssue is a reference of the source project that has no equivalent in the target environment. ExtLineItemID
+// is empty for references made by the project itself.
+type MappingIssue struct {
+	Kind          MappingKind
+	ExtLineItemID string
+	Value         string
+	Reason        string
+}
+
+func (i *MappingIssue) Error() string {
+	if len(i.ExtLineItemID) == 0 {
+		return fmt.Sprintf("%s %s: %s", i.Kind, i.Value, i.Reason)
+	}
+	return fmt.Sprintf("line item %s: %s %s: %s", i.ExtLineItemID, i.Kind, i.Value, i.Reason)
+}
+
+// PromotionError holds every reference that could not be mapped to the target environment.
+type PromotionError struct {
+	Issues []*MappingIssue
+}
+
+func (e *PromotionError) Error() string {
+	msgs := make([]string, 0, len(e.Issues))
+	for _, i := range e.Issues {
+		msgs = append(msgs, i.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Promotion is a project read from one environment, with its attributes, sources and survey topics remapped to the
+// ids used by another environment.
+type Promotion struct {
+	Criteria *CreateProjectCriteria
+	Issues   []*MappingIssue
+}
+
+// Err returns a *PromotionError if some references could not be mapped, nil otherwise.
+func (p *Promotion) Err() error {
+	if len(p.Issues) == 0 {
+		return nil
+	}
+	return &PromotionError{Issues: p.Issues}
+}
+
+// PlanPromotionWithContext reads a project from src and maps it to the environment of c. Attributes and their
+// options are matched by id, then by name and text. Sources are matched by name within the country and language of
+// each line item. Survey topics must exist in the target environment. Nothing is written to either environment.
+func (c *Client) PlanPromotionWithContext(ctx context.Context, src *Client, extProjectID string) (*Promotion, error) {
+	err := ValidateNotEmpty(extProjectID)
+	if err != nil {
+		return nil, err
+	}
+	res, err := src.GetProjectByWithContext(ctx, extProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if res.Project == nil {
+		return nil, ErrRequiredFieldEmpty
+	}
+	m := &promotionMapper{
+		src:        src,
+		dst:        c,
+		attributes: make(map[string]*attributeMapping),
+	}
+	criteria := res.Project.ToCreateCriteria()
+	if err := m.mapCategory(ctx, criteria.Category); err != nil {
+		return nil, err
+	}
+	for _, l := range criteria.LineItems {
+		if err := m.mapLineItem(ctx, l); err != nil {
+			return nil, err
+		}
+	}
+	return &Promotion{Criteria: criteria, Issues: m.issues}, nil
+}
+
+// PlanPromotion reads a project from src and maps it to the environment of c.
+func (c *Client) PlanPromotion(src *Client, extProjectID string) (*Promotion, error) {
+	return c.PlanPromotionWithContext(context.Background(), src, extProjectID)
+}
+
+// PromoteProjectWithContext recreates a project of src in the environment of c, typically from UAT to production.
+// If any reference cannot be mapped, a *PromotionError is returned and the project is not created.
+func (c *Client) PromoteProjectWithContext(ctx context.Context, src *Client, extProjectID string) (*ProjectResponse, error) {
+	promotion, err := c.PlanPromotionWithContext(ctx, src, extProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if err := promotion.Err(); err != nil {
+		return nil, err
+	}
+	return c.CreateProjectWithContext(ctx, promotion.Criteria)
+}
+
+// PromoteProject recreates a project of src in the environment of c, typically from UAT to production.
+func (c *Client) PromoteProject(src *Client, extProjectID string) (*ProjectResponse, error) {
+	return c.PromoteProjectWithContext(context.Background(), src, extProjectID)
+}
+
+type attributeMapping struct {
+	src      map[string]*Attribute
+	dstByID  map[string]*Attribute
+	dstByKey map[string]*Attribute
+}
+
+type promotionMapper struct {
+	src, dst   *Client
+	topics     map[string]bool
+	sources    []*SampleSource
+	attributes map[string]*attributeMapping
+	issues     []*MappingIssue
+}
+
+func (m *promotionMapper) issue(kind MappingKind, extLineItemID, value, reason string) {
+	m.issues = append(m.issues, &MappingIssue{Kind: kind, ExtLineItemID: extLineItemID, Value: value, Reason: reason})
+}
+
+func (m *promotionMapper) mapCategory(ctx context.Context, category *Category) error {
+	if category == nil {
+		return nil
+	}
+	if m.topics == nil {
+		topics, err := m.dst.AllSurveyTopicsWithContext(ctx)
+		if err != nil {
+			return err
+		}
+		m.topics = make(map[string]bool, len(topics))
+		for _, t := range topics {
+			m.topics[t.Topic] = true
+		}
+	}
+	for _, t := range category.SurveyTopic {
+		if !m.topics[t] {
+			m.issue(MappingSurveyTopic, "", t, "not found in the target environment")
+		}
+	}
+	return nil
+}
+
+func (m *promotionMapper) mapLineItem(ctx context.Context, l *CreateLineItemCriteria) error {
+	if len(l.Sources) > 0 {
+		if err := m.mapSources(ctx, l); err != nil {
+			return err
+		}
+	}
+	if l.QuotaPlan == nil {
+		return nil
+	}
+	am, err := m.attributeMapping(ctx, l.CountryISOCode, l.LanguageISOCode)
+	if err != nil {
+		return err
+	}
+	for _, f := range l.QuotaPlan.Filters {
+		f.AttributeID, f.Options = m.mapAttribute(am, l.ExtLineItemID, f.AttributeID, f.Options)
+	}
+	for _, g := range l.QuotaPlan.QuotaGroups {
+		for _, c := range g.QuotaCells {
+			for _, n := range c.QuotaNodes {
+				n.AttributeID, n.Options = m.mapAttribute(am, l.ExtLineItemID, n.AttributeID, n.Options)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *promotionMapper) mapSources(ctx context.Context, l *CreateLineItemCriteria) error {
+	if m.sources == nil {
+		sources, err := m.dst.AllSourcesWithContext(ctx)
+		if err != nil {
+			return err
+		}
+		m.sources = sources
+	}
+	var available []Sources
+	for _, s := range m.sources {
+		if strings.EqualFold(s.CountryISOCode, l.CountryISOCode) && strings.EqualFold(s.LanguageISOCode, l.LanguageISOCode) {
+			available = append(available, s.Sources...)
+		}
+	}
+	for _, s := range l.Sources {
+		mapped := false
+		for _, a := range available {
+			if (len(s.Name) > 0 && strings.EqualFold(a.Name, s.Name)) || (len(s.Name) == 0 && int64(a.ID) == s.ID) {
+				s.ID, s.Name = int64(a.ID), a.Name
+				mapped = true
+				break
+			}
+		}
+		if !mapped {
+			value := s.Name
+			if len(value) == 0 {
+				value = fmt.Sprint(s.ID)
+			}
+			m.issue(MappingSource, l.ExtLineItemID, value,
+				fmt.Sprintf("not available for %s/%s in the target environment", l.CountryISOCode, l.LanguageISOCode))
+		}
+	}
+	return nil
+}
+
+func (m *promotionMapper) attributeMapping(ctx context.Context, countryCode, languageCode string) (*attributeMapping, error) {
+	key := strings.ToUpper(countryCode) + "/" + strings.ToLower(languageCode)
+	if am, ok := m.attributes[key]; ok {
+		return am, nil
+	}
+	src, err := m.src.AllAttributesWithContext(ctx, countryCode, languageCode)
+	if err != nil {
+		return nil, err
+	}
+	dst, err := m.dst.AllAttributesWithContext(ctx, countryCode, languageCode)
+	if err != nil {
+		return nil, err
+	}
+	am := &attributeMapping{
+		src:      make(map[string]*Attribute, len(src)),
+		dstByID:  make(map[string]*Attribute, len(dst)),
+		dstByKey: make(map[string]*Attribute, len(dst)),
+	}
+	for _, a := range src {
+		am.src[a.ID] = a
+	}
+	for _, a := range dst {
+		am.dstByID[a.ID] = a
+		am.dstByKey[strings.ToLower(a.Name)] = a
+	}
+	m.attributes[key] = am
+	return am, nil
+}
+
+// mapAttribute returns the target ids of an attribute and its options. Unmapped ids are returned unchanged.
+func (m *promotionMapper) mapAttribute(am *attributeMapping, extLineItemID, id string, options []string) (string, []string) {
+	src := am.src[id]
+	dst := am.dstByID[id]
+	if src != nil && (dst == nil || !strings.EqualFold(dst.Name, src.Name)) {
+		dst = am.dstByKey[strings.ToLower(src.Name)]
+	}
+	if dst == nil {
+		m.issue(MappingAttribute, extLineItemID, id, "not found in the target environment")
+		return id, options
+	}
+	if dst.State == StateInactive {
+		m.issue(MappingAttribute, extLineItemID, id, fmt.Sprintf("%s is inactive in the target environment", dst.Name))
+	}
+	mapped := make([]string, 0, len(options))
+	for _, o := range options {
+		var option *AttributeOption
+		if src != nil {
+			if so := findAttributeOption(src, o, ""); so != nil {
+				option = findAttributeOption(dst, "", so.Text)
+			}
+		}
+		if option == nil && dst.ID == id {
+			option = findAttributeOption(dst, o, "")
+		}
+		if option == nil {
+			m.issue(MappingAttributeOption, extLineItemID, id+"="+o,
+				fmt.Sprintf("not an option of %s in the target environment", dst.Name))
+			mapped = append(mapped, o)
+			continue
+		}
+		mapped = append(mapped, option.ID)
+	}
+	return dst.ID, mapped
+}
+
+func findAttributeOption(a *Attribute, id, text string) *AttributeOption {
+	for _, o := range a.Options {
+		if (len(id) > 0 && o.ID == id) || (len(text) > 0 && strings.EqualFold(o.Text, text)) {
+			return o
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package samplify_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+const promoteSourceProject = `{"data": {
+	"extProjectId": "prj",
+	"title": "Tracker",
+	"notificationEmails": ["api-test@researchnow.com"],
+	"devices": ["mobile"],
+	"category": {"surveyTopic": ["AUTOMOTIVE"]},
+	"lineItems": [{
+		"extLineItemId": "li",
+		"title": "US",
+		"countryISOCode": "US",
+		"languageISOCode": "en",
+		"surveyURL": "www.mysurvey.com/live/survey",
+		"indicativeIncidence": 20,
+		"daysInField": 20,
+		"lengthOfInterview": 10,
+		"targets": [{"count": 100, "type": "COMPLETE"}],
+		"sources": [{"id": 100, "name": "Dynata"}],
+		"quotaPlan": {
+			"filters": [{"attributeId": "61961", "options": ["3"]}],
+			"quotaGroups": [{"name": "gender", "quotaCells": [
+				{"quotaNodes": [{"attributeId": "11", "options": ["1"]}], "perc": 50},
+				{"quotaNodes": [{"attributeId": "11", "options": ["2"]}], "perc": 50}
+			]}]
+		}
+	}]
+}}`
+
+const promoteSourceAttributes = `{"data": [
+	{"id": "11", "name": "GENDER", "options": [{"id": "1", "text": "Male"}, {"id": "2", "text": "Female"}]},
+	{"id": "61961", "name": "REGION", "options": [{"id": "3", "text": "North"}]}
+]}`
+
+func TestPromoteProject(t *testing.T) {
+	tables := []struct {
+		name       string
+		attributes string
+		sources    string
+		topics     string
+		issues     []string
+	}{
+		{
+			"Case 1: every reference is remapped",
+			`{"data": [
+				{"id": "11", "name": "GENDER", "options": [{"id": "1", "text": "Male"}, {"id": "2", "text": "Female"}]},
+				{"id": "70001", "name": "REGION", "options": [{"id": "9", "text": "North"}]}
+			]}`,
+			`{"data": [{"countryISOCode": "US", "languageISOCode": "en", "sources": [{"id": 200, "name": "Dynata"}]}]}`,
+			`{"data": [{"topic": "AUTOMOTIVE"}]}`,
+			nil,
+		},
+		{
+			"Case 2: unmapped references are reported and nothing is created",
+			`{"data": [{"id": "11", "name": "GENDER", "options": [{"id": "1", "text": "Male"}]}]}`,
+			`{"data": [{"countryISOCode": "GB", "languageISOCode": "en", "sources": [{"id": 200, "name": "Dynata"}]}]}`,
+			`{"data": []}`,
+			[]string{
+				"surveyTopic AUTOMOTIVE: not found in the target environment",
+				"line item li: source Dynata: not available for US/en in the target environment",
+				"line item li: attribute 61961: not found in the target environment",
+				"line item li: attributeOption 11=2: not an option of GENDER in the target environment",
+			},
+		},
+	}
+
+	src := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/projects/prj":
+			w.Write([]byte(promoteSourceProject))
+		case "/attributes/US/en":
+			w.Write([]byte(promoteSourceAttributes))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer src.Close()
+	srcClient := samplify.NewClient("", "", "", &samplify.ClientOptions{APIBaseURL: src.URL, AuthURL: src.URL})
+	srcClient.Auth = getAuth()
+
+	for _, table := range tables {
+		var created *samplify.CreateProjectCriteria
+		dst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == "/attributes/US/en":
+				w.Write([]byte(table.attributes))
+			case r.URL.Path == "/sources":
+				w.Write([]byte(table.sources))
+			case r.URL.Path == "/categories/surveyTopics":
+				w.Write([]byte(table.topics))
+			case r.Method == "POST" && r.URL.Path == "/projects":
+				b, _ := ioutil.ReadAll(r.Body)
+				created = &samplify.CreateProjectCriteria{}
+				json.Unmarshal(b, created)
+				w.Write([]byte(`{"data": {"extProjectId": "prj"}}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		dstClient := samplify.NewClient("", "", "", &samplify.ClientOptions{APIBaseURL: dst.URL, AuthURL: dst.URL})
+		dstClient.Auth = getAuth()
+
+		_, err := dstClient.PromoteProject(srcClient, "prj")
+		dst.Close()
+		if table.issues == nil {
+			if err != nil {
+				t.Fatalf("%s: %v", table.name, err)
+			}
+			if created == nil || len(created.LineItems) != 1 {
+				t.Fatalf("%s: the project was not created", table.name)
+			}
+			l := created.LineItems[0]
+			if l.Sources[0].ID != 200 {
+				t.Errorf("%s: got source %d, want 200", table.name, l.Sources[0].ID)
+			}
+			if f := l.QuotaPlan.Filters[0]; f.AttributeID != "70001" || f.Options[0] != "9" {
+				t.Errorf("%s: got filter %s=%v, want 70001=[9]", table.name, f.AttributeID, f.Options)
+			}
+			continue
+		}
+		if created != nil {
+			t.Errorf("%s: the project should not have been created", table.name)
+		}
+		perr, ok := err.(*samplify.PromotionError)
+		if !ok {
+			t.Fatalf("%s: expected a *PromotionError, got %v", table.name, err)
+		}
+		if len(perr.Issues) != len(table.issues) {
+			t.Fatalf("%s: got issues\n%v\nwant %d", table.name, perr, len(table.issues))
+		}
+		for i, issue := range perr.Issues {
+			if issue.Error() != table.issues[i] {
+				t.Errorf("%s: got issue `%s`, want `%s`", table.name, issue.Error(), table.issues[i])
+			}
+		}
+	}
+}
@@ -133,12 +133,12 @@ func query2String(options *QueryOptions) string {
 		sep := ""
 		if len(options.Scope) > 0 {
 			query = fmt.Sprintf("?scope=%s", options.Scope)
-			sep = "&amp;"
+			sep = "&"
 		}
 		if len(options.FilterBy) > 0 {
 			for _, f := range options.FilterBy {
 				query = fmt.Sprintf("%s%s%s=%s", query, sep, f.Field, f.Value.String())
-				sep = "&amp;"
+				sep = "&"
 			}
 		}
 		if len(options.SortBy) > 0 {
@@ -150,24 +150,26 @@ func query2String(options *QueryOptions) string {
 			}
 		}
 		if len(sep) > 0 {
-			sep = "&amp;"
+			sep = "&"
 		}
 		if options.Offset > 0 {
 			query = fmt.Sprintf("%s%soffset=%d", query, sep, options.Offset)
-			sep = "&amp;"
+			sep = "&"
 		}
 		if options.Limit > 0 {
 			if options.Limit > maxLimit {
 				options.Limit = maxLimit
 			}
 			query = fmt.Sprintf("%s%slimit=%d", query, sep, options.Limit)
+			sep = "&"
 		}
 		if options.ExtProjectId != nil {
 			query = fmt.Sprintf("%s%sextProjectId=%s", query, sep, *options.ExtProjectId)
-			sep = "&amp;"
+			sep = "&"
 		}
 		if options.ExtLineItemId != nil {
 			query = fmt.Sprintf("%s%sextLineItemId=%s", query, sep, *options.ExtLineItemId)
+			sep = "&"
 		}
 		if options.EventType != nil {
 			query = fmt.Sprintf("%s%seventType=%s", query, sep, *options.EventType)
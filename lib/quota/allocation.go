@@ -0,0 +1,213 @@
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+// PercTolerance is the difference allowed between the sum of the percentages of a quota group and 100.
+const PercTolerance = 0.01
+
+// Errors returned by the allocation utilities
+var (
+	ErrNoTarget    = errors.New("the line item has no complete target")
+	ErrPercTotal   = errors.New("the percentages of the quota cells do not add up to 100")
+	ErrCountTotal  = errors.New("the counts of the quota cells do not add up to the target")
+	ErrEmptyWeight = errors.New("the quota cells of the group have no allocation")
+	ErrBadWeight   = errors.New("weights must be finite and not negative")
+)
+
+// Target returns the number of completes of a line item.
+func Target(targets []*samplify.LineItemTarget) (uint32, error) {
+	for _, t := range targets {
+		if t.Type == samplify.TargetTypeComplete && t.Count != nil {
+			return *t.Count, nil
+		}
+	}
+	return 0, ErrNoTarget
+}
+
+// ValidateTotals checks that the percentages of every quota group add up to 100, and that the counts add up to the
+// target of the line item.
+func ValidateTotals(plan *samplify.QuotaPlan, target uint32) error {
+	if err := samplify.ValidateQuotaPlan(plan); err != nil {
+		return err
+	}
+	if plan == nil {
+		return nil
+	}
+	for i, g := range plan.QuotaGroups {
+		if g.QuotaCells[0].AllocationType() == samplify.AllocationPercentage {
+			var total float64
+			for _, c := range g.QuotaCells {
+				total += *c.Perc
+			}
+			if math.Abs(total-100) > PercTolerance {
+				return fmt.Errorf("%w: %s adds up to %.2f", ErrPercTotal, groupName(g, i), total)
+			}
+			continue
+		}
+		var total uint32
+		for _, c := range g.QuotaCells {
+			total += *c.Count
+		}
+		if total != target {
+			return fmt.Errorf("%w: %s adds up to %d instead of %d", ErrCountTotal, groupName(g, i), total, target)
+		}
+	}
+	return nil
+}
+
+// ToCounts returns a copy of the plan where the percentages are replaced by counts of the target. The counts of every
+// group add up to the target exactly, using the largest remainder method.
+func ToCounts(plan *samplify.QuotaPlan, target uint32) (*samplify.QuotaPlan, error) {
+	if err := ValidateTotals(plan, target); err != nil {
+		return nil, err
+	}
+	res := plan.Clone()
+	if res == nil {
+		return nil, nil
+	}
+	for _, g := range res.QuotaGroups {
+		if g.QuotaCells[0].AllocationType() != samplify.AllocationPercentage {
+			continue
+		}
+		counts, err := Apportion(target, cellWeights(g))
+		if err != nil {
+			return nil, err
+		}
+		for i, c := range g.QuotaCells {
+			count := counts[i]
+			c.Perc, c.Count = nil, &count
+		}
+	}
+	return res, nil
+}
+
+// ToPercentages returns a copy of the plan where the counts are replaced by percentages with two decimals. The
+// percentages of every group add up to 100 exactly, using the largest remainder method.
+func ToPercentages(plan *samplify.QuotaPlan) (*samplify.QuotaPlan, error) {
+	if err := samplify.ValidateQuotaPlan(plan); err != nil {
+		return nil, err
+	}
+	res := plan.Clone()
+	if res == nil {
+		return nil, nil
+	}
+	for i, g := range res.QuotaGroups {
+		if g.QuotaCells[0].AllocationType() != samplify.AllocationCount {
+			continue
+		}
+		weights := cellWeights(g)
+		if sum(weights) == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrEmptyWeight, groupName(g, i))
+		}
+		hundredths, err := Apportion(10000, weights)
+		if err != nil {
+			return nil, err
+		}
+		for j, c := range g.QuotaCells {
+			perc := float64(hundredths[j]) / 100
+			c.Perc, c.Count = &perc, nil
+		}
+	}
+	return res, nil
+}
+
+// Rescale returns a copy of the plan where the counts are scaled to a new target, keeping their proportions.
+// Percentages are left unchanged.
+func Rescale(plan *samplify.QuotaPlan, target uint32) (*samplify.QuotaPlan, error) {
+	if err := samplify.ValidateQuotaPlan(plan); err != nil {
+		return nil, err
+	}
+	res := plan.Clone()
+	if res == nil {
+		return nil, nil
+	}
+	for i, g := range res.QuotaGroups {
+		if g.QuotaCells[0].AllocationType() != samplify.AllocationCount {
+			continue
+		}
+		weights := cellWeights(g)
+		if sum(weights) == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrEmptyWeight, groupName(g, i))
+		}
+		counts, err := Apportion(target, weights)
+		if err != nil {
+			return nil, err
+		}
+		for j, c := range g.QuotaCells {
+			count := counts[j]
+			c.Count = &count
+		}
+	}
+	return res, nil
+}
+
+// Apportion splits total into integers proportional to the weights, using the largest remainder method: every
+// share is rounded down, then the units left are given to the largest remainders. Ties go to the first share. The
+// shares add up to total unless every weight is zero. Negative, infinite and NaN weights are refused.
+func Apportion(total uint32, weights []float64) ([]uint32, error) {
+	for i, weight := range weights {
+		if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return nil, fmt.Errorf("%w: %v at index %d", ErrBadWeight, weight, i)
+		}
+	}
+	shares := make([]uint32, len(weights))
+	w := sum(weights)
+	if w <= 0 {
+		return shares, nil
+	}
+	remainders := make([]float64, len(weights))
+	var allocated uint32
+	for i, weight := range weights {
+		exact := float64(total) * weight / w
+		shares[i] = uint32(math.Floor(exact))
+		remainders[i] = exact - math.Floor(exact)
+		allocated += shares[i]
+	}
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainders[order[a]] > remainders[order[b]]
+	})
+	for i := 0; allocated < total; i++ {
+		shares[order[i%len(order)]]++
+		allocated++
+	}
+	return shares, nil
+}
+
+func cellWeights(g *samplify.QuotaGroup) []float64 {
+	weights := make([]float64, len(g.QuotaCells))
+	for i, c := range g.QuotaCells {
+		switch {
+		case c.Perc != nil:
+			weights[i] = *c.Perc
+		case c.Count != nil:
+			weights[i] = float64(*c.Count)
+		}
+	}
+	return weights
+}
+
+func sum(values []float64) float64 {
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+func groupName(g *samplify.QuotaGroup, i int) string {
+	if g.Name != nil && len(*g.Name) > 0 {
+		return fmt.Sprintf("quota group %q", *g.Name)
+	}
+	return fmt.Sprintf("quota group %d", i+1)
+}
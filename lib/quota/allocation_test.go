@@ -0,0 +1,129 @@
+package quota_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+	"github.com/morningconsult/go-samplifyapi-client/lib/quota"
+)
+
+func percPlan(percs ...float64) *samplify.QuotaPlan {
+	g := &samplify.QuotaGroup{}
+	for i := range percs {
+		g.QuotaCells = append(g.QuotaCells, &samplify.QuotaCell{Perc: &percs[i]})
+	}
+	return &samplify.QuotaPlan{QuotaGroups: []*samplify.QuotaGroup{g}}
+}
+
+func countPlan(counts ...uint32) *samplify.QuotaPlan {
+	g := &samplify.QuotaGroup{}
+	for i := range counts {
+		g.QuotaCells = append(g.QuotaCells, &samplify.QuotaCell{Count: &counts[i]})
+	}
+	return &samplify.QuotaPlan{QuotaGroups: []*samplify.QuotaGroup{g}}
+}
+
+func allocations(plan *samplify.QuotaPlan) string {
+	var res []string
+	for _, c := range plan.QuotaGroups[0].QuotaCells {
+		if c.Perc != nil {
+			res = append(res, fmt.Sprint(*c.Perc))
+		} else {
+			res = append(res, fmt.Sprint(*c.Count))
+		}
+	}
+	return fmt.Sprint(res)
+}
+
+func TestApportion(t *testing.T) {
+	tables := []struct {
+		total    uint32
+		weights  []float64
+		expected string
+	}{
+		{100, []float64{1, 1, 1}, "[34 33 33]"},
+		{10, []float64{33.33, 33.33, 33.34}, "[3 3 4]"},
+		{7, []float64{50, 30, 20}, "[4 2 1]"},
+		{5, []float64{0, 0}, "[0 0]"},
+		{0, []float64{1, 2}, "[0 0]"},
+		{10, []float64{5, -1}, "[]"},
+		{10, []float64{1, math.NaN()}, "[]"},
+		{10, []float64{math.Inf(1), 1}, "[]"},
+	}
+
+	for _, table := range tables {
+		shares, err := quota.Apportion(table.total, table.weights)
+		if got := fmt.Sprint(shares); got != table.expected {
+			t.Errorf("Apportion(%d, %v): got %s, want %s", table.total, table.weights, got, table.expected)
+		}
+		if invalid := table.expected == "[]"; invalid != errors.Is(err, quota.ErrBadWeight) {
+			t.Errorf("Apportion(%d, %v): unexpected error %v", table.total, table.weights, err)
+		}
+	}
+}
+
+func TestValidateTotals(t *testing.T) {
+	tables := []struct {
+		name   string
+		plan   *samplify.QuotaPlan
+		target uint32
+		err    error
+	}{
+		{"Case 1: percentages add up to 100", percPlan(33.33, 33.33, 33.34), 100, nil},
+		{"Case 2: percentages add up to 90", percPlan(45, 45), 100, quota.ErrPercTotal},
+		{"Case 3: counts add up to the target", countPlan(60, 40), 100, nil},
+		{"Case 4: counts exceed the target", countPlan(60, 50), 100, quota.ErrCountTotal},
+		{"Case 5: no plan", nil, 100, nil},
+	}
+
+	for _, table := range tables {
+		if err := quota.ValidateTotals(table.plan, table.target); !errors.Is(err, table.err) {
+			t.Errorf("%s: got error %v, want %v", table.name, err, table.err)
+		}
+	}
+}
+
+func TestConversions(t *testing.T) {
+	plan := percPlan(33.33, 33.33, 33.34)
+	counts, err := quota.ToCounts(plan, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := allocations(counts); got != "[67 66 67]" {
+		t.Errorf("ToCounts: got %s, want [67 66 67]", got)
+	}
+	if allocations(plan) != "[33.33 33.33 33.34]" {
+		t.Errorf("ToCounts changed the original plan: %s", allocations(plan))
+	}
+
+	percs, err := quota.ToPercentages(countPlan(1, 1, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := allocations(percs); got != "[33.34 33.33 33.33]" {
+		t.Errorf("ToPercentages: got %s, want [33.34 33.33 33.33]", got)
+	}
+
+	rescaled, err := quota.Rescale(countPlan(60, 30, 10), 55)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := allocations(rescaled); got != "[33 17 5]" {
+		t.Errorf("Rescale: got %s, want [33 17 5]", got)
+	}
+	if err := quota.ValidateTotals(rescaled, 55); err != nil {
+		t.Errorf("Rescale: %v", err)
+	}
+
+	if _, err := quota.ToPercentages(countPlan(0, 0)); !errors.Is(err, quota.ErrEmptyWeight) {
+		t.Errorf("ToPercentages: got error %v, want ErrEmptyWeight", err)
+	}
+
+	count := uint32(300)
+	if target, err := quota.Target([]*samplify.LineItemTarget{{Count: &count, Type: samplify.TargetTypeComplete}}); err != nil || target != 300 {
+		t.Errorf("Target: got %d, %v", target, err)
+	}
+}
@@ -0,0 +1,200 @@
+// Package quota builds, checks and computes quota plans for line items.
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+// Errors returned by the Builder
+var (
+	ErrUnknownAttribute    = errors.New("unknown attribute")
+	ErrUnknownOption       = errors.New("unknown attribute option")
+	ErrNoOptions           = errors.New("at least one option must be given")
+	ErrNotAllowedInFilters = errors.New("attribute is not allowed in filters")
+	ErrNotAllowedInQuotas  = errors.New("attribute is not allowed in quotas")
+	ErrAttributeNotActive  = errors.New("attribute is not active")
+)
+
+// Node selects options of an attribute, by attribute id, name or text and by option id or text.
+type Node struct {
+	Attribute string
+	Options   []string
+}
+
+// Builder builds a quota plan from the attributes returned by GetAttributes. The first error stops the build and is
+// returned by Build.
+type Builder struct {
+	attributes []*samplify.Attribute
+	plan       *samplify.QuotaPlan
+	err        error
+}
+
+// NewBuilder returns a builder of quota plans using the given attributes.
+func NewBuilder(attributes []*samplify.Attribute) *Builder {
+	return &Builder{attributes: attributes, plan: &samplify.QuotaPlan{}}
+}
+
+// Filter only includes the respondents with one of the options of the attribute.
+func (b *Builder) Filter(attribute string, options ...string) *Builder {
+	return b.filter(samplify.OperatorInclude, attribute, options)
+}
+
+// Exclude excludes the respondents with one of the options of the attribute.
+func (b *Builder) Exclude(attribute string, options ...string) *Builder {
+	return b.filter(samplify.OperatorExclude, attribute, options)
+}
+
+func (b *Builder) filter(operator samplify.Operator, attribute string, options []string) *Builder {
+	if b.err != nil {
+		return b
+	}
+	a, ids, err := resolve(b.attributes, attribute, options)
+	if err != nil {
+		b.err = err
+		return b
+	}
+	if !a.IsAllowedInFilters {
+		b.err = fmt.Errorf("%w: %s", ErrNotAllowedInFilters, a.Name)
+		return b
+	}
+	b.plan.Filters = append(b.plan.Filters, &samplify.QuotaFilters{AttributeID: a.ID, Options: ids, Operator: &operator})
+	return b
+}
+
+// Group starts a new quota group.
+func (b *Builder) Group(name string) *GroupBuilder {
+	group := &samplify.QuotaGroup{Name: &name}
+	b.plan.QuotaGroups = append(b.plan.QuotaGroups, group)
+	return &GroupBuilder{b: b, group: group}
+}
+
+// Build returns the quota plan, or the first error met while building it or by ValidateQuotaPlan.
+func (b *Builder) Build() (*samplify.QuotaPlan, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if err := samplify.ValidateQuotaPlan(b.plan); err != nil {
+		return nil, err
+	}
+	return b.plan, nil
+}
+
+// resolve returns the attribute and the ids of its options.
+func resolve(attributes []*samplify.Attribute, attribute string, options []string) (*samplify.Attribute, []string, error) {
+	a := FindAttribute(attributes, attribute)
+	if a == nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnknownAttribute, attribute)
+	}
+	if a.State == samplify.StateDeprecated || a.State == samplify.StateInactive {
+		return nil, nil, fmt.Errorf("%w: %s is %s", ErrAttributeNotActive, a.Name, a.State)
+	}
+	if len(options) == 0 {
+		return nil, nil, fmt.Errorf("%w: %s", ErrNoOptions, a.Name)
+	}
+	ids := make([]string, 0, len(options))
+	for _, o := range options {
+		option := FindOption(a, o)
+		if option == nil {
+			return nil, nil, fmt.Errorf("%w: %s of %s", ErrUnknownOption, o, a.Name)
+		}
+		ids = append(ids, option.ID)
+	}
+	return a, ids, nil
+}
+
+// GroupBuilder adds cells to a quota group.
+type GroupBuilder struct {
+	b     *Builder
+	group *samplify.QuotaGroup
+}
+
+// Perc adds a cell with a percentage of the completes for the options of an attribute.
+func (g *GroupBuilder) Perc(perc float64, attribute string, options ...string) *GroupBuilder {
+	return g.PercCell(perc, Node{Attribute: attribute, Options: options})
+}
+
+// Count adds a cell with a number of completes for the options of an attribute.
+func (g *GroupBuilder) Count(count uint32, attribute string, options ...string) *GroupBuilder {
+	return g.CountCell(count, Node{Attribute: attribute, Options: options})
+}
+
+// PercCell adds a cell with a percentage of the completes for the respondents matching every node.
+func (g *GroupBuilder) PercCell(perc float64, nodes ...Node) *GroupBuilder {
+	return g.cell(&samplify.QuotaCell{Perc: &perc}, nodes)
+}
+
+// CountCell adds a cell with a number of completes for the respondents matching every node.
+func (g *GroupBuilder) CountCell(count uint32, nodes ...Node) *GroupBuilder {
+	return g.cell(&samplify.QuotaCell{Count: &count}, nodes)
+}
+
+func (g *GroupBuilder) cell(cell *samplify.QuotaCell, nodes []Node) *GroupBuilder {
+	if g.b.err != nil {
+		return g
+	}
+	for _, n := range nodes {
+		a, ids, err := resolve(g.b.attributes, n.Attribute, n.Options)
+		if err != nil {
+			g.b.err = err
+			return g
+		}
+		if !a.IsAllowedInQuotas {
+			g.b.err = fmt.Errorf("%w: %s", ErrNotAllowedInQuotas, a.Name)
+			return g
+		}
+		cell.QuotaNodes = append(cell.QuotaNodes, &samplify.QuotaNode{AttributeID: a.ID, Options: ids})
+	}
+	g.group.QuotaCells = append(g.group.QuotaCells, cell)
+	return g
+}
+
+// Group ends this group and starts a new one.
+func (g *GroupBuilder) Group(name string) *GroupBuilder {
+	return g.b.Group(name)
+}
+
+// End ends this group and returns the plan builder.
+func (g *GroupBuilder) End() *Builder {
+	return g.b
+}
+
+// Build returns the quota plan.
+func (g *GroupBuilder) Build() (*samplify.QuotaPlan, error) {
+	return g.b.Build()
+}
+
+// FindAttribute returns the attribute with the given id, or else name or text, ignoring case. It returns nil if
+// there is none.
+func FindAttribute(attributes []*samplify.Attribute, attribute string) *samplify.Attribute {
+	for _, a := range attributes {
+		if a.ID == attribute {
+			return a
+		}
+	}
+	for _, a := range attributes {
+		if strings.EqualFold(a.Name, attribute) || strings.EqualFold(a.Text, attribute) {
+			return a
+		}
+	}
+	return nil
+}
+
+// FindOption returns the option of the attribute with the given id, or else text, ignoring case. It returns nil if
+// there is none.
+func FindOption(a *samplify.Attribute, option string) *samplify.AttributeOption {
+	for _, o := range a.Options {
+		if o.ID == option {
+			return o
+		}
+	}
+	for _, o := range a.Options {
+		if strings.EqualFold(o.Text, option) || (o.LocalizedText != nil && strings.EqualFold(*o.LocalizedText, option)) {
+			return o
+		}
+	}
+	return nil
+}
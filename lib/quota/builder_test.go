@@ -0,0 +1,88 @@
+package quota_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+	"github.com/morningconsult/go-samplifyapi-client/lib/quota"
+)
+
+const attributesJSON = `[
+	{"id": "11", "name": "GENDER", "text": "Gender", "isAllowedInFilters": true, "isAllowedInQuotas": true, "state": "ACTIVE",
+		"options": [{"id": "1", "text": "Male"}, {"id": "2", "text": "Female"}]},
+	{"id": "13", "name": "AGE_GROUP", "text": "Age group", "isAllowedInFilters": true, "isAllowedInQuotas": true, "state": "ACTIVE",
+		"options": [{"id": "1", "text": "18-34"}, {"id": "2", "text": "35-54"}, {"id": "3", "text": "55+"}]},
+	{"id": "61961", "name": "REGION", "text": "Region", "isAllowedInFilters": true, "isAllowedInQuotas": false, "state": "ACTIVE",
+		"options": [{"id": "1", "text": "North"}, {"id": "2", "text": "South"}]},
+	{"id": "4091", "name": "INCOME", "text": "Income", "isAllowedInFilters": false, "isAllowedInQuotas": true, "state": "DEPRECATED",
+		"options": [{"id": "3", "text": "High"}]}
+]`
+
+func testAttributes(t *testing.T) []*samplify.Attribute {
+	var attributes []*samplify.Attribute
+	if err := json.Unmarshal([]byte(attributesJSON), &attributes); err != nil {
+		t.Fatal(err)
+	}
+	return attributes
+}
+
+func TestBuilder(t *testing.T) {
+	attributes := testAttributes(t)
+
+	plan, err := quota.NewBuilder(attributes).
+		Filter("region", "North").
+		Exclude("AGE_GROUP", "55+").
+		Group("Gender").Perc(50, "Gender", "Male").Perc(50, "GENDER", "2").
+		Group("Age").Count(60, "13", "18-34").Count(40, "AGE_GROUP", "35-54").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Filters) != 2 || plan.Filters[0].AttributeID != "61961" || plan.Filters[0].Options[0] != "1" ||
+		*plan.Filters[1].Operator != samplify.OperatorExclude || plan.Filters[1].Options[0] != "3" {
+		t.Errorf("unexpected filters %+v", plan.Filters)
+	}
+	if len(plan.QuotaGroups) != 2 || *plan.QuotaGroups[0].Name != "Gender" || len(plan.QuotaGroups[1].QuotaCells) != 2 {
+		t.Fatalf("unexpected quota groups %+v", plan.QuotaGroups)
+	}
+	if n := plan.QuotaGroups[0].QuotaCells[1].QuotaNodes[0]; n.AttributeID != "11" || n.Options[0] != "2" {
+		t.Errorf("unexpected quota node %+v", n)
+	}
+
+	tables := []struct {
+		name  string
+		build func(b *quota.Builder) (*samplify.QuotaPlan, error)
+		err   error
+	}{
+		{"Case 1: unknown attribute", func(b *quota.Builder) (*samplify.QuotaPlan, error) {
+			return b.Filter("COLOR", "Red").Build()
+		}, quota.ErrUnknownAttribute},
+		{"Case 2: unknown option", func(b *quota.Builder) (*samplify.QuotaPlan, error) {
+			return b.Group("Gender").Perc(100, "GENDER", "Other").Build()
+		}, quota.ErrUnknownOption},
+		{"Case 3: not allowed in quotas", func(b *quota.Builder) (*samplify.QuotaPlan, error) {
+			return b.Group("Region").Perc(100, "REGION", "North").Build()
+		}, quota.ErrNotAllowedInQuotas},
+		{"Case 4: deprecated attribute", func(b *quota.Builder) (*samplify.QuotaPlan, error) {
+			return b.Group("Income").Perc(100, "INCOME", "High").Build()
+		}, quota.ErrAttributeNotActive},
+		{"Case 5: mixed allocations", func(b *quota.Builder) (*samplify.QuotaPlan, error) {
+			return b.Group("Gender").Perc(50, "GENDER", "Male").Count(50, "GENDER", "Female").Build()
+		}, samplify.ErrInconsistentAllocationType},
+		{"Case 6: empty group", func(b *quota.Builder) (*samplify.QuotaPlan, error) {
+			return b.Group("Gender").Build()
+		}, samplify.ErrMissingQuotaCells},
+		{"Case 7: first error wins", func(b *quota.Builder) (*samplify.QuotaPlan, error) {
+			return b.Filter("GENDER").Filter("COLOR", "Red").Build()
+		}, quota.ErrNoOptions},
+	}
+
+	for _, table := range tables {
+		plan, err := table.build(quota.NewBuilder(attributes))
+		if plan != nil || !errors.Is(err, table.err) {
+			t.Errorf("%s: got error %v, want %v", table.name, err, table.err)
+		}
+	}
+}
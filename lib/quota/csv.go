@@ -0,0 +1,355 @@
+package quota
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+// OptionSeparator separates the options of a node in the options column.
+const OptionSeparator = "|"
+
+// Errors returned when reading a quota CSV
+var (
+	ErrMissingColumn = errors.New("missing column")
+	ErrInvalidRow    = errors.New("invalid row")
+)
+
+// CSV columns
+const (
+	ColumnGroup     = "group"
+	ColumnCell      = "cell"
+	ColumnAttribute = "attribute"
+	ColumnOptions   = "options"
+	ColumnPerc      = "perc"
+	ColumnCount     = "count"
+	ColumnStatus    = "status"
+)
+
+var (
+	planHeader   = []string{ColumnGroup, ColumnCell, ColumnAttribute, ColumnOptions, ColumnPerc, ColumnCount, ColumnStatus}
+	reportHeader = []string{ColumnGroup, ColumnCell, ColumnAttribute, ColumnOptions, ColumnPerc, ColumnCount, "attempts", "completes", "remainingCompletes", "screenouts", "overquotas", "incidenceRate"}
+)
+
+// ReadCSV reads a quota plan from a CSV with a header row. The group, attribute, options and perc or count columns
+// are required; cell and status are optional. Columns are matched by name ignoring case, and attributes and options
+// by id, name or text. Options are separated by OptionSeparator. Each row is a cell, unless rows of a group share the
+// same cell value: they are then the nodes of one interlocked cell, and the allocation and status are read from the
+// first of them. Errors give the line of the row, counting the header as line 1.
+func ReadCSV(r io.Reader, attributes []*samplify.Attribute) (*samplify.QuotaPlan, error) {
+	br := bufio.NewReader(r)
+	if err := samplify.SkipByteOrderMark(br); err != nil {
+		return nil, err
+	}
+	cr := csv.NewReader(br)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, h := range header {
+		columns[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, c := range []string{ColumnGroup, ColumnAttribute, ColumnOptions} {
+		if _, ok := columns[c]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrMissingColumn, c)
+		}
+	}
+	_, hasPerc := columns[ColumnPerc]
+	_, hasCount := columns[ColumnCount]
+	if !hasPerc && !hasCount {
+		return nil, fmt.Errorf("%w: %s or %s", ErrMissingColumn, ColumnPerc, ColumnCount)
+	}
+
+	plan := &samplify.QuotaPlan{}
+	groups := make(map[string]*samplify.QuotaGroup)
+	cells := make(map[string]*samplify.QuotaCell)
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		if isBlank(record) {
+			continue
+		}
+		fail := func(err error) (*samplify.QuotaPlan, error) {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		name := field(ColumnGroup)
+		if len(name) == 0 {
+			return fail(fmt.Errorf("%w: empty %s", ErrInvalidRow, ColumnGroup))
+		}
+		a, ids, err := resolve(attributes, field(ColumnAttribute), splitOptions(field(ColumnOptions)))
+		if err != nil {
+			return fail(err)
+		}
+		if !a.IsAllowedInQuotas {
+			return fail(fmt.Errorf("%w: %s", ErrNotAllowedInQuotas, a.Name))
+		}
+		node := &samplify.QuotaNode{AttributeID: a.ID, Options: ids}
+
+		group, ok := groups[name]
+		if !ok {
+			n := name
+			group = &samplify.QuotaGroup{Name: &n}
+			groups[name] = group
+			plan.QuotaGroups = append(plan.QuotaGroups, group)
+		}
+		key := field(ColumnCell)
+		if len(key) > 0 {
+			if cell, ok := cells[name+"\x00"+key]; ok {
+				cell.QuotaNodes = append(cell.QuotaNodes, node)
+				continue
+			}
+		}
+		cell, err := parseCell(field(ColumnPerc), field(ColumnCount), field(ColumnStatus))
+		if err != nil {
+			return fail(err)
+		}
+		cell.QuotaNodes = []*samplify.QuotaNode{node}
+		group.QuotaCells = append(group.QuotaCells, cell)
+		if len(key) > 0 {
+			cells[name+"\x00"+key] = cell
+		}
+	}
+	if err := samplify.ValidateQuotaPlan(plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// ImportCSVWithContext reads a quota plan from a CSV, resolving the attributes and options with those available for
+// the country and language.
+func ImportCSVWithContext(ctx context.Context, c *samplify.Client, countryCode, languageCode string, r io.Reader) (*samplify.QuotaPlan, error) {
+	attributes, err := c.AllAttributesWithContext(ctx, countryCode, languageCode)
+	if err != nil {
+		return nil, err
+	}
+	return ReadCSV(r, attributes)
+}
+
+// ImportCSV reads a quota plan from a CSV, resolving the attributes and options for the country and language.
+func ImportCSV(c *samplify.Client, countryCode, languageCode string, r io.Reader) (*samplify.QuotaPlan, error) {
+	return ImportCSVWithContext(context.Background(), c, countryCode, languageCode, r)
+}
+
+// WriteCSV writes the quota groups of a plan as CSV, with one row per node and the text of the attributes and
+// options. Ids that are not in attributes are written as is. Filters are not written. The output starts with a UTF-8
+// byte order mark and uses CRLF line endings, like the files opened in spreadsheet applications.
+func WriteCSV(w io.Writer, plan *samplify.QuotaPlan, attributes []*samplify.Attribute) error {
+	cw, err := newCSVWriter(w, planHeader)
+	if err != nil {
+		return err
+	}
+	if plan != nil {
+		for i, g := range plan.QuotaGroups {
+			for j, c := range g.QuotaCells {
+				var status string
+				if c.Status != nil {
+					status = string(*c.Status)
+				}
+				for _, n := range c.QuotaNodes {
+					row := []string{groupLabel(g, i), cellLabel(c.QuotaCellID, j), attributeText(attributes, n.AttributeID),
+						optionsText(attributes, n), formatPerc(c.Perc), formatCount(c.Count), status}
+					if err := cw.Write(row); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteReportCSV writes the quota groups of a detailed line item report as CSV, with one row per node and the stats
+// of its cell. The plan is optional: when given, the groups are named and the cells allocated from it, matching them
+// by id.
+func WriteReportCSV(w io.Writer, groups []*samplify.DetailedQuotaGroupReport, plan *samplify.QuotaPlan, attributes []*samplify.Attribute) error {
+	cw, err := newCSVWriter(w, reportHeader)
+	if err != nil {
+		return err
+	}
+	names := make(map[string]string)
+	planned := make(map[string]*samplify.QuotaCell)
+	if plan != nil {
+		for i, g := range plan.QuotaGroups {
+			if g.QuotaGroupID != nil {
+				names[*g.QuotaGroupID] = groupLabel(g, i)
+			}
+			for _, c := range g.QuotaCells {
+				if c.QuotaCellID != nil {
+					planned[*c.QuotaCellID] = c
+				}
+			}
+		}
+	}
+	for _, g := range groups {
+		name, ok := names[g.QuotaGroupID]
+		if !ok {
+			name = g.QuotaGroupID
+		}
+		for j, c := range g.QuotaCells {
+			var perc, count string
+			if p, ok := planned[c.QuotaCellID]; ok {
+				perc, count = formatPerc(p.Perc), formatCount(p.Count)
+			}
+			s := c.Stats
+			for _, n := range c.QuotaNodes {
+				row := []string{name, cellLabel(&c.QuotaCellID, j), attributeText(attributes, n.AttributeID),
+					optionsText(attributes, n), perc, count,
+					strconv.FormatInt(s.Attempts, 10), strconv.FormatInt(s.Completes, 10),
+					strconv.FormatInt(s.RemainingCompletes, 10), strconv.FormatInt(s.Screenouts, 10),
+					strconv.FormatInt(s.Overquotas, 10), strconv.FormatFloat(s.IncidenceRate, 'f', -1, 64)}
+				if err := cw.Write(row); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func newCSVWriter(w io.Writer, header []string) (*csv.Writer, error) {
+	if err := samplify.WriteByteOrderMark(w); err != nil {
+		return nil, err
+	}
+	cw := csv.NewWriter(w)
+	cw.UseCRLF = true
+	return cw, cw.Write(header)
+}
+
+func parseCell(perc, count, status string) (*samplify.QuotaCell, error) {
+	cell := &samplify.QuotaCell{}
+	switch {
+	case len(perc) > 0 && len(count) > 0:
+		return nil, fmt.Errorf("%w: both %s and %s are set", ErrInvalidRow, ColumnPerc, ColumnCount)
+	case len(perc) > 0:
+		p, err := strconv.ParseFloat(strings.TrimSuffix(perc, "%"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s %q", ErrInvalidRow, ColumnPerc, perc)
+		}
+		cell.Perc = &p
+	case len(count) > 0:
+		c, err := strconv.ParseUint(count, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s %q", ErrInvalidRow, ColumnCount, count)
+		}
+		n := uint32(c)
+		cell.Count = &n
+	default:
+		return nil, fmt.Errorf("%w: empty %s and %s", ErrInvalidRow, ColumnPerc, ColumnCount)
+	}
+	if len(status) > 0 {
+		s := samplify.QCellStatusType(strings.ToUpper(status))
+		if s != samplify.QCellStatusTypeLaunch && s != samplify.QCellStatusTypePause {
+			return nil, fmt.Errorf("%w: %s %q", ErrInvalidRow, ColumnStatus, status)
+		}
+		cell.Status = &s
+	}
+	return cell, nil
+}
+
+func splitOptions(s string) []string {
+	var options []string
+	for _, o := range strings.Split(s, OptionSeparator) {
+		if o = strings.TrimSpace(o); len(o) > 0 {
+			options = append(options, o)
+		}
+	}
+	return options
+}
+
+func isBlank(record []string) bool {
+	for _, f := range record {
+		if len(strings.TrimSpace(f)) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func groupLabel(g *samplify.QuotaGroup, i int) string {
+	if g.Name != nil && len(*g.Name) > 0 {
+		return *g.Name
+	}
+	return strconv.Itoa(i + 1)
+}
+
+func cellLabel(id *string, i int) string {
+	if id != nil && len(*id) > 0 {
+		return *id
+	}
+	return strconv.Itoa(i + 1)
+}
+
+func attributeText(attributes []*samplify.Attribute, id string) string {
+	for _, a := range attributes {
+		if a.ID == id {
+			if len(a.Text) > 0 {
+				return a.Text
+			}
+			return a.Name
+		}
+	}
+	return id
+}
+
+func optionsText(attributes []*samplify.Attribute, n *samplify.QuotaNode) string {
+	var a *samplify.Attribute
+	for _, attr := range attributes {
+		if attr.ID == n.AttributeID {
+			a = attr
+			break
+		}
+	}
+	texts := make([]string, 0, len(n.Options))
+	for _, id := range n.Options {
+		text := id
+		if a != nil {
+			for _, o := range a.Options {
+				if o.ID == id && len(o.Text) > 0 {
+					text = o.Text
+					break
+				}
+			}
+		}
+		texts = append(texts, text)
+	}
+	return strings.Join(texts, OptionSeparator)
+}
+
+func formatPerc(p *float64) string {
+	if p == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*p, 'f', -1, 64)
+}
+
+func formatCount(c *uint32) string {
+	if c == nil {
+		return ""
+	}
+	return strconv.FormatUint(uint64(*c), 10)
+}
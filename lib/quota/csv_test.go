@@ -0,0 +1,143 @@
+package quota_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+	"github.com/morningconsult/go-samplifyapi-client/lib/quota"
+)
+
+func TestReadCSV(t *testing.T) {
+	attributes := testAttributes(t)
+	tables := []struct {
+		name     string
+		csv      string
+		expected string
+		err      error
+	}{
+		{
+			name:     "Case 1: one cell per row",
+			csv:      "Group,Attribute,Options,Perc,Status\nGender,Gender,Male,50,launched\nGender,GENDER,2,50%,\n\nAge,Age group,18-34|35-54,60,\nAge,13,55+,40,PAUSED\n",
+			expected: "[[11[1]]=50 [11[2]]=50] [[13[1 2]]=60 [13[3]]=40]",
+		},
+		{
+			name:     "Case 2: interlocked cells",
+			csv:      "\xEF\xBB\xBFgroup,cell,attribute,options,count\nG x A,a,Gender,Male,30\nG x A,a,Age group,18-34,\nG x A,b,Gender,Female,70\nG x A,b,Age group,35-54|55+,\n",
+			expected: "[[11[1] 13[1]]=30 [11[2] 13[2 3]]=70]",
+		},
+		{
+			name: "Case 3: missing column",
+			csv:  "group,attribute,perc\nGender,Gender,50\n",
+			err:  quota.ErrMissingColumn,
+		},
+		{
+			name: "Case 4: unknown option",
+			csv:  "group,attribute,options,perc\nGender,Gender,Male,50\nGender,Gender,Other,50\n",
+			err:  quota.ErrUnknownOption,
+		},
+		{
+			name: "Case 5: invalid percentage",
+			csv:  "group,attribute,options,perc\nGender,Gender,Male,half\n",
+			err:  quota.ErrInvalidRow,
+		},
+		{
+			name: "Case 6: invalid status",
+			csv:  "group,attribute,options,perc,status\nGender,Gender,Male,100,STOPPED\n",
+			err:  quota.ErrInvalidRow,
+		},
+		{
+			name: "Case 7: mixed allocations",
+			csv:  "group,attribute,options,perc,count\nGender,Gender,Male,50,\nGender,Gender,Female,,50\n",
+			err:  samplify.ErrInconsistentAllocationType,
+		},
+	}
+
+	for _, table := range tables {
+		plan, err := quota.ReadCSV(strings.NewReader(table.csv), attributes)
+		if !errors.Is(err, table.err) {
+			t.Errorf("%s: got error %v, want %v", table.name, err, table.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		var groups []string
+		for _, g := range plan.QuotaGroups {
+			groups = append(groups, describeGroup(g))
+		}
+		if got := strings.Join(groups, " "); got != table.expected {
+			t.Errorf("%s: got %s, want %s", table.name, got, table.expected)
+		}
+	}
+
+	_, err := quota.ReadCSV(strings.NewReader("group,attribute,options,perc\nGender,Gender,Male,50\nGender,Gender,Other,50\n"), attributes)
+	if err == nil || !strings.HasPrefix(err.Error(), "line 3:") {
+		t.Errorf("ReadCSV: got error %v, want it on line 3", err)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	attributes := testAttributes(t)
+	status := samplify.QCellStatusTypePause
+	plan, err := quota.NewBuilder(attributes).
+		Group("Gender x Age").
+		CountCell(30, quota.Node{Attribute: "GENDER", Options: []string{"Male"}}, quota.Node{Attribute: "AGE_GROUP", Options: []string{"1", "2"}}).
+		CountCell(70, quota.Node{Attribute: "GENDER", Options: []string{"Female"}}, quota.Node{Attribute: "AGE_GROUP", Options: []string{"3"}}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan.QuotaGroups[0].QuotaCells[1].Status = &status
+
+	buf := &bytes.Buffer{}
+	if err := quota.WriteCSV(buf, plan, attributes); err != nil {
+		t.Fatal(err)
+	}
+	expected := "\xEF\xBB\xBFgroup,cell,attribute,options,perc,count,status\r\n" +
+		"Gender x Age,1,Gender,Male,,30,\r\n" +
+		"Gender x Age,1,Age group,18-34|35-54,,30,\r\n" +
+		"Gender x Age,2,Gender,Female,,70,PAUSED\r\n" +
+		"Gender x Age,2,Age group,55+,,70,PAUSED\r\n"
+	if buf.String() != expected {
+		t.Errorf("WriteCSV: got %q, want %q", buf.String(), expected)
+	}
+
+	read, err := quota.ReadCSV(buf, attributes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := describeGroup(read.QuotaGroups[0]), describeGroup(plan.QuotaGroups[0]); got != want {
+		t.Errorf("ReadCSV(WriteCSV): got %s, want %s", got, want)
+	}
+	if s := read.QuotaGroups[0].QuotaCells[1].Status; s == nil || *s != status {
+		t.Errorf("ReadCSV(WriteCSV): got status %v, want %s", s, status)
+	}
+}
+
+func TestWriteReportCSV(t *testing.T) {
+	attributes := testAttributes(t)
+	groupID, cellID, name := "g1", "c1", "Gender"
+	perc := 100.0
+	plan := &samplify.QuotaPlan{QuotaGroups: []*samplify.QuotaGroup{{QuotaGroupID: &groupID, Name: &name, QuotaCells: []*samplify.QuotaCell{
+		{QuotaCellID: &cellID, Perc: &perc, QuotaNodes: []*samplify.QuotaNode{{AttributeID: "11", Options: []string{"1"}}}},
+	}}}}
+	groups := []*samplify.DetailedQuotaGroupReport{{QuotaGroupID: groupID, QuotaCells: []*samplify.DetailedQuotaCellReport{
+		{QuotaCellID: cellID, QuotaNodes: []*samplify.QuotaNode{{AttributeID: "11", Options: []string{"1"}}},
+			Stats: samplify.DetailedStats{Attempts: 10, Completes: 4, RemainingCompletes: 6, Screenouts: 5, Overquotas: 1, IncidenceRate: 0.4}},
+		{QuotaCellID: "c2", QuotaNodes: []*samplify.QuotaNode{{AttributeID: "99", Options: []string{"7"}}}},
+	}}}
+
+	buf := &bytes.Buffer{}
+	if err := quota.WriteReportCSV(buf, groups, plan, attributes); err != nil {
+		t.Fatal(err)
+	}
+	expected := "\xEF\xBB\xBFgroup,cell,attribute,options,perc,count,attempts,completes,remainingCompletes,screenouts,overquotas,incidenceRate\r\n" +
+		"Gender,c1,Gender,Male,100,,10,4,6,5,1,0.4\r\n" +
+		"Gender,c2,99,7,,,0,0,0,0,0,0\r\n"
+	if buf.String() != expected {
+		t.Errorf("WriteReportCSV: got %q, want %q", buf.String(), expected)
+	}
+}
@@ -0,0 +1,310 @@
+package quota
+
+import (
+	"context"
+	"sort"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+// RelaxationKind is the kind of change suggested to make a quota cell feasible.
+type RelaxationKind string
+
+// RelaxationKind values
+const (
+	RelaxationMergeOptions RelaxationKind = "MERGE_OPTIONS"
+	RelaxationLowerCount   RelaxationKind = "LOWER_COUNT"
+)
+
+// CellGap compares the completes requested for a quota cell with the feasibility count of the same nodes. Matched is
+// false when the feasibility has no count for the cell.
+type CellGap struct {
+	GroupIndex int
+	CellIndex  int
+	Group      string
+	QuotaNodes []*samplify.QuotaNode
+	Requested  uint32
+	Available  int64
+	Matched    bool
+	Shortfall  uint32
+}
+
+// Infeasible tells whether fewer respondents are available than requested.
+func (g *CellGap) Infeasible() bool {
+	return g.Matched && g.Shortfall > 0
+}
+
+// Relaxation is a suggested change of a quota group: Cell replaces the cells of the group at the indexes of Cells.
+// Target is the line item target after the change and Projected the completes expected with it.
+type Relaxation struct {
+	Kind       RelaxationKind
+	GroupIndex int
+	Cells      []int
+	Cell       *samplify.QuotaCell
+	Target     uint32
+	Projected  uint32
+}
+
+// GapAnalysis compares the quota plan of a line item with its feasibility. Cells are only analyzed when the
+// feasibility is READY.
+type GapAnalysis struct {
+	ExtLineItemID string
+	Status        samplify.FeasibilityStatus
+	Target        uint32
+	TotalCount    int64
+	Cells         []*CellGap
+	// Projected is the number of completes expected with the current plan: the target, capped by the respondents
+	// available in every group.
+	Projected   uint32
+	Relaxations []*Relaxation
+}
+
+// Infeasible returns the cells with a shortfall.
+func (a *GapAnalysis) Infeasible() []*CellGap {
+	var res []*CellGap
+	for _, c := range a.Cells {
+		if c.Infeasible() {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// AnalyzeGaps joins the feasibility counts to the cells of the plan by their nodes, whatever their order, and
+// computes the shortfall of every cell against the target. Percentages are converted to counts of the target. For
+// every infeasible cell it suggests merging its options with the neighbor cell, differing by the options of a single
+// node, that has the most respondents to spare, and lowering its count to the respondents available. The suggested
+// cells have counts.
+func AnalyzeGaps(plan *samplify.QuotaPlan, target uint32, f *samplify.Feasibility) (*GapAnalysis, error) {
+	a := &GapAnalysis{Target: target, Projected: target}
+	if f == nil {
+		a.Status = samplify.FeasibilityStatusProcessing
+		return a, nil
+	}
+	a.Status, a.TotalCount = f.Status, f.TotalCount
+	if f.Status != samplify.FeasibilityStatusReady {
+		return a, nil
+	}
+	if err := samplify.ValidateQuotaPlan(plan); err != nil {
+		return nil, err
+	}
+
+	available := make(map[string]int64)
+	for _, v := range f.ValueCounts {
+		for _, c := range v.QuotaCells {
+			available[cellKey(&samplify.QuotaCell{QuotaNodes: c.QuotaNodes})] = c.FeasibilityCount
+		}
+	}
+	var groups [][]*CellGap
+	if plan != nil {
+		for i, g := range plan.QuotaGroups {
+			var requested []uint32
+			if g.QuotaCells[0].AllocationType() == samplify.AllocationPercentage {
+				var err error
+				requested, err = Apportion(target, cellWeights(g))
+				if err != nil {
+					return nil, err
+				}
+			} else {
+				for _, c := range g.QuotaCells {
+					requested = append(requested, *c.Count)
+				}
+			}
+			var cells []*CellGap
+			for j, c := range g.QuotaCells {
+				gap := &CellGap{GroupIndex: i, CellIndex: j, Group: groupName(g, i), QuotaNodes: c.QuotaNodes, Requested: requested[j]}
+				gap.Available, gap.Matched = available[cellKey(c)]
+				if gap.Matched && gap.Available < int64(gap.Requested) {
+					gap.Shortfall = gap.Requested - uint32(max64(gap.Available, 0))
+				}
+				cells = append(cells, gap)
+			}
+			groups = append(groups, cells)
+			a.Cells = append(a.Cells, cells...)
+		}
+	}
+	a.Projected = projected(groups, target, a.TotalCount)
+
+	for _, gap := range a.Infeasible() {
+		if r := mergeRelaxation(groups, gap, target, a.TotalCount); r != nil {
+			a.Relaxations = append(a.Relaxations, r)
+		}
+		count := uint32(max64(gap.Available, 0))
+		lowered := *gap
+		lowered.Requested, lowered.Shortfall = count, 0
+		relaxed := replaceCells(groups, gap.GroupIndex, []int{gap.CellIndex}, &lowered)
+		a.Relaxations = append(a.Relaxations, &Relaxation{
+			Kind:       RelaxationLowerCount,
+			GroupIndex: gap.GroupIndex,
+			Cells:      []int{gap.CellIndex},
+			Cell:       &samplify.QuotaCell{QuotaNodes: copyNodes(gap.QuotaNodes), Count: &count},
+			Target:     target - gap.Shortfall,
+			Projected:  projected(relaxed, target-gap.Shortfall, a.TotalCount),
+		})
+	}
+	return a, nil
+}
+
+// AnalyzeProjectGapsWithContext analyzes the feasibility of every line item of a project.
+func AnalyzeProjectGapsWithContext(ctx context.Context, c *samplify.Client, extProjectID string) ([]*GapAnalysis, error) {
+	project, err := c.GetProjectByWithContext(ctx, extProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if project.Project == nil {
+		return nil, samplify.ErrRequiredFieldEmpty
+	}
+	feasibility, err := c.AllFeasibilityWithContext(ctx, extProjectID)
+	if err != nil {
+		return nil, err
+	}
+	var res []*GapAnalysis
+	for _, l := range project.Project.LineItems {
+		var f *samplify.Feasibility
+		for _, lf := range feasibility.List {
+			if lf.ExtLineItemID == l.ExtLineItemID {
+				f = lf.Feasibility
+			}
+		}
+		target, _ := Target(l.Targets)
+		a, err := AnalyzeGaps(l.QuotaPlan, target, f)
+		if err != nil {
+			return nil, err
+		}
+		a.ExtLineItemID = l.ExtLineItemID
+		res = append(res, a)
+	}
+	return res, nil
+}
+
+// AnalyzeProjectGaps analyzes the feasibility of every line item of a project.
+func AnalyzeProjectGaps(c *samplify.Client, extProjectID string) ([]*GapAnalysis, error) {
+	return AnalyzeProjectGapsWithContext(context.Background(), c, extProjectID)
+}
+
+// mergeRelaxation suggests merging an infeasible cell with the neighbor with the most respondents to spare, if the
+// merged cell is feasible.
+func mergeRelaxation(groups [][]*CellGap, gap *CellGap, target uint32, total int64) *Relaxation {
+	var best *CellGap
+	node := -1
+	for _, c := range groups[gap.GroupIndex] {
+		if c == gap || !c.Matched {
+			continue
+		}
+		n := differingNode(gap.QuotaNodes, c.QuotaNodes)
+		if n < 0 || c.Available+gap.Available < int64(c.Requested+gap.Requested) {
+			continue
+		}
+		if best == nil || c.Available-int64(c.Requested) > best.Available-int64(best.Requested) {
+			best, node = c, n
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	nodes := copyNodes(gap.QuotaNodes)
+	for _, n := range best.QuotaNodes {
+		if n.AttributeID == nodes[node].AttributeID {
+			nodes[node].Options = append(nodes[node].Options, n.Options...)
+		}
+	}
+	merged := &CellGap{
+		QuotaNodes: nodes,
+		Requested:  gap.Requested + best.Requested,
+		Available:  gap.Available + best.Available,
+		Matched:    true,
+	}
+	indexes := []int{gap.CellIndex, best.CellIndex}
+	sort.Ints(indexes)
+	count := merged.Requested
+	return &Relaxation{
+		Kind:       RelaxationMergeOptions,
+		GroupIndex: gap.GroupIndex,
+		Cells:      indexes,
+		Cell:       &samplify.QuotaCell{QuotaNodes: nodes, Count: &count},
+		Target:     target,
+		Projected:  projected(replaceCells(groups, gap.GroupIndex, indexes, merged), target, total),
+	}
+}
+
+// projected returns the completes expected for a target: every group caps it at the sum of the completes its cells
+// can get, and the total count of the feasibility caps it as well. Cells without a feasibility count get what they
+// request.
+func projected(groups [][]*CellGap, target uint32, total int64) uint32 {
+	res := target
+	if total > 0 && total < int64(res) {
+		res = uint32(total)
+	}
+	for _, cells := range groups {
+		var sum uint32
+		for _, c := range cells {
+			sum += c.Requested - c.Shortfall
+		}
+		if sum < res {
+			res = sum
+		}
+	}
+	return res
+}
+
+// replaceCells returns a copy of the groups where the cells at the indexes of a group are replaced by one cell.
+func replaceCells(groups [][]*CellGap, group int, indexes []int, cell *CellGap) [][]*CellGap {
+	res := make([][]*CellGap, len(groups))
+	copy(res, groups)
+	cells := []*CellGap{cell}
+	for i, c := range groups[group] {
+		replaced := false
+		for _, j := range indexes {
+			replaced = replaced || i == j
+		}
+		if !replaced {
+			cells = append(cells, c)
+		}
+	}
+	res[group] = cells
+	return res
+}
+
+// differingNode returns the index of the only node of a whose options differ from the node of b with the same
+// attribute, or -1 if the cells do not have the same attributes or differ by zero or several nodes.
+func differingNode(a, b []*samplify.QuotaNode) int {
+	if len(a) != len(b) {
+		return -1
+	}
+	diff := -1
+	for i, n := range a {
+		var other *samplify.QuotaNode
+		for _, m := range b {
+			if m.AttributeID == n.AttributeID {
+				other = m
+				break
+			}
+		}
+		if other == nil {
+			return -1
+		}
+		if cellKey(&samplify.QuotaCell{QuotaNodes: []*samplify.QuotaNode{n}}) == cellKey(&samplify.QuotaCell{QuotaNodes: []*samplify.QuotaNode{other}}) {
+			continue
+		}
+		if diff >= 0 {
+			return -1
+		}
+		diff = i
+	}
+	return diff
+}
+
+func copyNodes(nodes []*samplify.QuotaNode) []*samplify.QuotaNode {
+	res := make([]*samplify.QuotaNode, len(nodes))
+	for i, n := range nodes {
+		res[i] = &samplify.QuotaNode{AttributeID: n.AttributeID, Options: append([]string{}, n.Options...)}
+	}
+	return res
+}
+
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
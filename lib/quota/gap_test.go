@@ -0,0 +1,107 @@
+package quota_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+	"github.com/morningconsult/go-samplifyapi-client/lib/quota"
+)
+
+func TestAnalyzeGaps(t *testing.T) {
+	plan, err := quota.NewBuilder(testAttributes(t)).
+		Group("Gender").Perc(50, "GENDER", "Male").Perc(50, "GENDER", "Female").
+		Group("Age").Count(30, "AGE_GROUP", "18-34").Count(40, "AGE_GROUP", "35-54").Count(30, "AGE_GROUP", "55+").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := func(attributeID, option string, n int64) *samplify.FeasibilityQuotaCell {
+		return &samplify.FeasibilityQuotaCell{FeasibilityCount: n, QuotaNodes: []*samplify.QuotaNode{{AttributeID: attributeID, Options: []string{option}}}}
+	}
+	f := &samplify.Feasibility{Status: samplify.FeasibilityStatusReady, TotalCount: 105, ValueCounts: []*samplify.ValueCount{
+		{QuotaCells: []*samplify.FeasibilityQuotaCell{count("11", "1", 60), count("11", "2", 45)}},
+		{QuotaCells: []*samplify.FeasibilityQuotaCell{count("13", "1", 10), count("13", "2", 60)}},
+	}}
+
+	a, err := quota.AnalyzeGaps(plan, 100, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cells []string
+	for _, c := range a.Cells {
+		cells = append(cells, fmt.Sprintf("%d/%d:%d/%d/%v/%d", c.GroupIndex, c.CellIndex, c.Requested, c.Available, c.Matched, c.Shortfall))
+	}
+	expectedCells := "[0/0:50/60/true/0 0/1:50/45/true/5 1/0:30/10/true/20 1/1:40/60/true/0 1/2:30/0/false/0]"
+	if got := fmt.Sprint(cells); got != expectedCells {
+		t.Errorf("cells: got %s, want %s", got, expectedCells)
+	}
+	if len(a.Infeasible()) != 2 {
+		t.Errorf("infeasible: got %d cells, want 2", len(a.Infeasible()))
+	}
+	if a.Projected != 80 {
+		t.Errorf("projected: got %d, want 80", a.Projected)
+	}
+
+	var relaxations []string
+	for _, r := range a.Relaxations {
+		relaxations = append(relaxations, fmt.Sprintf("%s %d%v %s=%d target=%d projected=%d", r.Kind, r.GroupIndex, r.Cells,
+			describeGroup(&samplify.QuotaGroup{QuotaCells: []*samplify.QuotaCell{r.Cell}}), *r.Cell.Count, r.Target, r.Projected))
+	}
+	expected := []string{
+		"MERGE_OPTIONS 0[0 1] [[11[2 1]]=100]=100 target=100 projected=80",
+		"LOWER_COUNT 0[1] [[11[2]]=45]=45 target=95 projected=80",
+		"MERGE_OPTIONS 1[0 1] [[13[1 2]]=70]=70 target=100 projected=95",
+		"LOWER_COUNT 1[0] [[13[1]]=10]=10 target=80 projected=80",
+	}
+	if fmt.Sprint(relaxations) != fmt.Sprint(expected) {
+		t.Errorf("relaxations: got\n%v\nwant\n%v", relaxations, expected)
+	}
+
+	a, err = quota.AnalyzeGaps(plan, 100, &samplify.Feasibility{Status: samplify.FeasibilityStatusProcessing})
+	if err != nil || len(a.Cells) != 0 || a.Status != samplify.FeasibilityStatusProcessing {
+		t.Errorf("processing: got %+v, %v", a, err)
+	}
+}
+
+func TestAnalyzeProjectGapsPages(t *testing.T) {
+	const count = 1200
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var items []string
+		if r.URL.Path == "/projects/prj" {
+			for i := 0; i < count; i++ {
+				items = append(items, fmt.Sprintf(`{"extLineItemId": "li%d"}`, i))
+			}
+			fmt.Fprintf(w, `{"data": {"extProjectId": "prj", "lineItems": [%s]}}`, strings.Join(items, ","))
+			return
+		}
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		for i := offset; i < count && i < offset+limit; i++ {
+			items = append(items, fmt.Sprintf(`{"extLineItemId": "li%d", "feasibility": {"status": "READY", "totalCount": 100}}`, i))
+		}
+		fmt.Fprintf(w, `{"data": [%s]}`, strings.Join(items, ","))
+	}))
+	defer ts.Close()
+	now := time.Now()
+	client := samplify.NewClient("", "", "", &samplify.ClientOptions{APIBaseURL: ts.URL, AuthURL: ts.URL})
+	client.Auth = samplify.TokenResponse{AccessToken: "test", Acquired: &now, ExpiresIn: 1800}
+
+	res, err := quota.AnalyzeProjectGaps(client, "prj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != count {
+		t.Fatalf("got %d analyses, want %d", len(res), count)
+	}
+	for _, a := range res {
+		if a.Status != samplify.FeasibilityStatusReady {
+			t.Errorf("%s: got status %s, want READY", a.ExtLineItemID, a.Status)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+// Errors returned by Interlock
+var (
+	ErrNoDimensions = errors.New("at least one dimension must be given")
+	ErrCellTooSmall = errors.New("interlocked quota cell is smaller than the minimum cell size")
+)
+
+// Share is the percentage of the completes expected for some options of an attribute. Several options in one share
+// are counted together.
+type Share struct {
+	Options []string
+	Perc    float64
+}
+
+// Dimension is the marginal distribution of an attribute. The percentages of its shares add up to 100.
+type Dimension struct {
+	Attribute string
+	Shares    []Share
+}
+
+// InterlockOptions configures Interlock.
+type InterlockOptions struct {
+	// Name is the name of the quota group.
+	Name string
+	// Target is the number of completes. The cells get counts if set, and percentages otherwise.
+	Target uint32
+	// MinCellSize is the smallest allocation of a cell, as a count if Target is set and as a percentage otherwise.
+	MinCellSize float64
+	// MergeSmallCells merges the cells below MinCellSize with their smallest neighbor, the cells differing by a single
+	// attribute. Otherwise a cell below MinCellSize is an error.
+	MergeSmallCells bool
+}
+
+// interlockCell is a combination of shares, one set of shares per dimension.
+type interlockCell struct {
+	shares [][]int
+	size   float64
+}
+
+// Interlock returns a quota group with a cell for every combination of the shares of the dimensions, e.g. age ×
+// gender × region. The dimensions are assumed independent: the allocation of a cell is the product of the shares of
+// its options. The allocations of the cells add up to 100 or to the target exactly, and the minimum cell size is
+// checked before rounding.
+func Interlock(attributes []*samplify.Attribute, dimensions []Dimension, opts *InterlockOptions) (*samplify.QuotaGroup, error) {
+	o := InterlockOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if len(dimensions) == 0 {
+		return nil, ErrNoDimensions
+	}
+
+	attrs := make([]*samplify.Attribute, len(dimensions))
+	ids := make([][][]string, len(dimensions))
+	for i, d := range dimensions {
+		var total float64
+		for _, s := range d.Shares {
+			a, options, err := resolve(attributes, d.Attribute, s.Options)
+			if err != nil {
+				return nil, err
+			}
+			if !a.IsAllowedInQuotas {
+				return nil, fmt.Errorf("%w: %s", ErrNotAllowedInQuotas, a.Name)
+			}
+			attrs[i] = a
+			ids[i] = append(ids[i], options)
+			total += s.Perc
+		}
+		if len(d.Shares) == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrNoOptions, d.Attribute)
+		}
+		if math.Abs(total-100) > PercTolerance {
+			return nil, fmt.Errorf("%w: %s adds up to %.2f", ErrPercTotal, attrs[i].Name, total)
+		}
+	}
+
+	scale := float64(100)
+	if o.Target > 0 {
+		scale = float64(o.Target)
+	}
+	cells := []*interlockCell{{size: scale}}
+	for _, d := range dimensions {
+		next := make([]*interlockCell, 0, len(cells)*len(d.Shares))
+		for _, c := range cells {
+			for j, s := range d.Shares {
+				shares := append(append([][]int{}, c.shares...), []int{j})
+				next = append(next, &interlockCell{shares: shares, size: c.size * s.Perc / 100})
+			}
+		}
+		cells = next
+	}
+
+	if o.MinCellSize > 0 {
+		var err error
+		cells, err = mergeSmallCells(cells, o.MinCellSize, o.MergeSmallCells, func(c *interlockCell) string {
+			return describeCell(dimensions, c)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	weights := make([]float64, len(cells))
+	for i, c := range cells {
+		weights[i] = c.size
+	}
+	group := &samplify.QuotaGroup{}
+	if len(o.Name) > 0 {
+		name := o.Name
+		group.Name = &name
+	}
+	total := uint32(10000)
+	if o.Target > 0 {
+		total = o.Target
+	}
+	allocations, err := Apportion(total, weights)
+	if err != nil {
+		return nil, err
+	}
+	for i, c := range cells {
+		cell := &samplify.QuotaCell{}
+		if o.Target > 0 {
+			count := allocations[i]
+			cell.Count = &count
+		} else {
+			perc := float64(allocations[i]) / 100
+			cell.Perc = &perc
+		}
+		for d, shares := range c.shares {
+			var options []string
+			for _, s := range shares {
+				options = append(options, ids[d][s]...)
+			}
+			cell.QuotaNodes = append(cell.QuotaNodes, &samplify.QuotaNode{AttributeID: attrs[d].ID, Options: options})
+		}
+		group.QuotaCells = append(group.QuotaCells, cell)
+	}
+	if err := samplify.ValidateQuotaPlan(&samplify.QuotaPlan{QuotaGroups: []*samplify.QuotaGroup{group}}); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// mergeSmallCells merges the smallest cell below min with its smallest neighbor until every cell reaches min. The
+// merged cell takes the place of the first of the two.
+func mergeSmallCells(cells []*interlockCell, min float64, merge bool, describe func(*interlockCell) string) ([]*interlockCell, error) {
+	for len(cells) > 1 {
+		small := -1
+		for i, c := range cells {
+			if c.size < min && (small < 0 || c.size < cells[small].size) {
+				small = i
+			}
+		}
+		if small < 0 {
+			break
+		}
+		tooSmall := fmt.Errorf("%w: %s is %.2f", ErrCellTooSmall, describe(cells[small]), cells[small].size)
+		if !merge {
+			return nil, tooSmall
+		}
+		neighbor, dim := -1, -1
+		for i, c := range cells {
+			if d := differingDimension(cells[small], c); d >= 0 && (neighbor < 0 || c.size < cells[neighbor].size) {
+				neighbor, dim = i, d
+			}
+		}
+		if neighbor < 0 {
+			return nil, tooSmall
+		}
+		first, second := small, neighbor
+		if second < first {
+			first, second = second, first
+		}
+		merged := cells[first]
+		merged.shares[dim] = append(merged.shares[dim], cells[second].shares[dim]...)
+		merged.size += cells[second].size
+		cells = append(cells[:second], cells[second+1:]...)
+	}
+	return cells, nil
+}
+
+// differingDimension returns the only dimension where the cells have different shares, or -1 if there is none or
+// several.
+func differingDimension(a, b *interlockCell) int {
+	dim := -1
+	for d := range a.shares {
+		if sameShares(a.shares[d], b.shares[d]) {
+			continue
+		}
+		if dim >= 0 {
+			return -1
+		}
+		dim = d
+	}
+	return dim
+}
+
+func sameShares(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, x := range a {
+		found := false
+		for _, y := range b {
+			if x == y {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func describeCell(dimensions []Dimension, c *interlockCell) string {
+	parts := make([]string, len(c.shares))
+	for d, shares := range c.shares {
+		var options []string
+		for _, s := range shares {
+			options = append(options, dimensions[d].Shares[s].Options...)
+		}
+		parts[d] = fmt.Sprintf("%s=%s", dimensions[d].Attribute, strings.Join(options, "|"))
+	}
+	return strings.Join(parts, ", ")
+}
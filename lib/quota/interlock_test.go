@@ -0,0 +1,106 @@
+package quota_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+	"github.com/morningconsult/go-samplifyapi-client/lib/quota"
+)
+
+func describeGroup(g *samplify.QuotaGroup) string {
+	var res []string
+	for _, c := range g.QuotaCells {
+		var nodes []string
+		for _, n := range c.QuotaNodes {
+			nodes = append(nodes, fmt.Sprintf("%s%v", n.AttributeID, n.Options))
+		}
+		if c.Perc != nil {
+			res = append(res, fmt.Sprintf("%v=%v", nodes, *c.Perc))
+		} else {
+			res = append(res, fmt.Sprintf("%v=%d", nodes, *c.Count))
+		}
+	}
+	return fmt.Sprint(res)
+}
+
+func TestInterlock(t *testing.T) {
+	attributes := testAttributes(t)
+	gender := quota.Dimension{Attribute: "GENDER", Shares: []quota.Share{
+		{Options: []string{"Male"}, Perc: 50},
+		{Options: []string{"Female"}, Perc: 50},
+	}}
+	age := quota.Dimension{Attribute: "AGE_GROUP", Shares: []quota.Share{
+		{Options: []string{"18-34"}, Perc: 30},
+		{Options: []string{"35-54"}, Perc: 40},
+		{Options: []string{"55+"}, Perc: 30},
+	}}
+	skewedAge := quota.Dimension{Attribute: "AGE_GROUP", Shares: []quota.Share{
+		{Options: []string{"18-34"}, Perc: 5},
+		{Options: []string{"35-54"}, Perc: 45},
+		{Options: []string{"55+"}, Perc: 50},
+	}}
+
+	tables := []struct {
+		name       string
+		dimensions []quota.Dimension
+		opts       *quota.InterlockOptions
+		expected   string
+		err        error
+	}{
+		{
+			name:       "Case 1: counts",
+			dimensions: []quota.Dimension{gender, age},
+			opts:       &quota.InterlockOptions{Name: "Gender x Age", Target: 200},
+			expected:   "[[11[1] 13[1]]=30 [11[1] 13[2]]=40 [11[1] 13[3]]=30 [11[2] 13[1]]=30 [11[2] 13[2]]=40 [11[2] 13[3]]=30]",
+		},
+		{
+			name:       "Case 2: percentages add up to 100",
+			dimensions: []quota.Dimension{gender, age},
+			expected:   "[[11[1] 13[1]]=15 [11[1] 13[2]]=20 [11[1] 13[3]]=15 [11[2] 13[1]]=15 [11[2] 13[2]]=20 [11[2] 13[3]]=15]",
+		},
+		{
+			name:       "Case 3: small cells are merged with their smallest neighbor",
+			dimensions: []quota.Dimension{gender, skewedAge},
+			opts:       &quota.InterlockOptions{Target: 100, MinCellSize: 5, MergeSmallCells: true},
+			expected:   "[[11[1 2] 13[1]]=5 [11[1] 13[2]]=23 [11[1] 13[3]]=25 [11[2] 13[2]]=22 [11[2] 13[3]]=25]",
+		},
+		{
+			name:       "Case 4: small cells are an error without merging",
+			dimensions: []quota.Dimension{gender, skewedAge},
+			opts:       &quota.InterlockOptions{Target: 100, MinCellSize: 5},
+			err:        quota.ErrCellTooSmall,
+		},
+		{
+			name: "Case 5: shares do not add up to 100",
+			dimensions: []quota.Dimension{gender, {Attribute: "AGE_GROUP", Shares: []quota.Share{
+				{Options: []string{"18-34"}, Perc: 30},
+			}}},
+			err: quota.ErrPercTotal,
+		},
+		{
+			name:       "Case 6: attribute not allowed in quotas",
+			dimensions: []quota.Dimension{{Attribute: "REGION", Shares: []quota.Share{{Options: []string{"North"}, Perc: 100}}}},
+			err:        quota.ErrNotAllowedInQuotas,
+		},
+		{
+			name: "Case 7: no dimensions",
+			err:  quota.ErrNoDimensions,
+		},
+	}
+
+	for _, table := range tables {
+		group, err := quota.Interlock(attributes, table.dimensions, table.opts)
+		if !errors.Is(err, table.err) {
+			t.Errorf("%s: got error %v, want %v", table.name, err, table.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got := describeGroup(group); got != table.expected {
+			t.Errorf("%s: got %s, want %s", table.name, got, table.expected)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+// Errors returned by the rebalancing
+var (
+	ErrNotRebalanceable = errors.New("the line item cannot be rebalanced in its current state")
+	ErrOverDelivered    = errors.New("the completes delivered exceed the target")
+)
+
+// CellBalance is the planned, delivered and proposed completes of a quota cell.
+type CellBalance struct {
+	GroupIndex  int
+	CellIndex   int
+	QuotaCellID string
+	Planned     uint32
+	Delivered   int64
+	// Remaining is the number of completes the cell still needed with the current plan.
+	Remaining uint32
+	Proposed  uint32
+}
+
+// Rebalancing is a proposed quota plan for a line item in field.
+type Rebalancing struct {
+	ExtLineItemID string
+	Target        uint32
+	Cells         []*CellBalance
+	// Plan is the proposed quota plan. Groups keep their allocation type.
+	Plan *samplify.QuotaPlan
+	// lineItem is the line item the plan is proposed for, to build the update criteria.
+	lineItem *samplify.LineItem
+}
+
+// Changed tells whether the proposed plan changes the allocation of any cell.
+func (r *Rebalancing) Changed() bool {
+	for _, c := range r.Cells {
+		if c.Proposed != c.Planned {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateCriteria returns the criteria to submit the proposed plan with UpdateLineItem. The field schedule of the line
+// item is kept when it has one, and its days in field otherwise.
+func (r *Rebalancing) UpdateCriteria() *samplify.UpdateLineItemCriteria {
+	criteria := &samplify.UpdateLineItemCriteria{ExtLineItemID: r.ExtLineItemID, QuotaPlan: r.Plan.Clone()}
+	if r.lineItem == nil {
+		return criteria
+	}
+	if s := r.lineItem.FieldSchedule; s != nil {
+		schedule := *s
+		criteria.FieldSchedule = &schedule
+		return criteria
+	}
+	if days := r.lineItem.DaysInField; days > 0 {
+		criteria.DaysInField = &days
+	}
+	return criteria
+}
+
+// Rebalance proposes a plan that keeps the target of the line item and never allocates a cell fewer completes than
+// it delivered. In every group, the completes still missing to reach the target are shared between the cells in
+// proportion to the completes they still needed, or to their planned allocation if none still needed any. Report
+// cells are matched to the plan by quota cell id. Percentages are converted to counts of the target and back.
+func Rebalance(l *samplify.LineItem, report *samplify.DetailedLineItemReport) (*Rebalancing, error) {
+	if !l.IsRebalanceable() {
+		return nil, fmt.Errorf("%w: %s", ErrNotRebalanceable, l.State)
+	}
+	target, err := Target(l.Targets)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := ToCounts(l.QuotaPlan, target)
+	if err != nil {
+		return nil, err
+	}
+	r := &Rebalancing{ExtLineItemID: l.ExtLineItemID, Target: target, Plan: l.QuotaPlan.Clone(), lineItem: l}
+	if counts == nil {
+		return r, nil
+	}
+
+	delivered := make(map[string]int64)
+	if report != nil {
+		for _, g := range report.QuotaGroups {
+			for _, c := range g.QuotaCells {
+				delivered[c.QuotaCellID] = c.Stats.Completes
+			}
+		}
+	}
+	for i, g := range counts.QuotaGroups {
+		cells := make([]*CellBalance, len(g.QuotaCells))
+		var total int64
+		for j, c := range g.QuotaCells {
+			b := &CellBalance{GroupIndex: i, CellIndex: j, Planned: *c.Count}
+			if c.QuotaCellID != nil {
+				b.QuotaCellID = *c.QuotaCellID
+				b.Delivered = delivered[b.QuotaCellID]
+			}
+			if b.Delivered < int64(b.Planned) {
+				b.Remaining = b.Planned - uint32(b.Delivered)
+			}
+			total += b.Delivered
+			cells[j] = b
+		}
+		if total > int64(target) {
+			return nil, fmt.Errorf("%w: %s delivered %d of %d", ErrOverDelivered, groupName(g, i), total, target)
+		}
+
+		weights := make([]float64, len(cells))
+		for j, b := range cells {
+			weights[j] = float64(b.Remaining)
+		}
+		if sum(weights) == 0 {
+			weights = cellWeights(g)
+		}
+		shares, err := Apportion(target-uint32(total), weights)
+		if err != nil {
+			return nil, err
+		}
+		proposed := make([]uint32, len(cells))
+		for j, b := range cells {
+			b.Proposed = uint32(b.Delivered) + shares[j]
+			proposed[j] = b.Proposed
+		}
+		r.Cells = append(r.Cells, cells...)
+
+		group := r.Plan.QuotaGroups[i]
+		if group.QuotaCells[0].AllocationType() == samplify.AllocationPercentage {
+			// Planned and proposed counts are compared, while the plan keeps its percentages.
+			hundredths, err := Apportion(10000, toWeights(proposed))
+			if err != nil {
+				return nil, err
+			}
+			for j, c := range group.QuotaCells {
+				perc := float64(hundredths[j]) / 100
+				c.Perc = &perc
+			}
+			continue
+		}
+		for j, c := range group.QuotaCells {
+			count := proposed[j]
+			c.Count = &count
+		}
+	}
+	return r, nil
+}
+
+// ProposeRebalanceWithContext reads a line item and its detailed report, and proposes a rebalanced quota plan.
+func ProposeRebalanceWithContext(ctx context.Context, c *samplify.Client, extProjectID, extLineItemID string) (*Rebalancing, error) {
+	res, err := c.GetLineItemByWithContext(ctx, extProjectID, extLineItemID)
+	if err != nil {
+		return nil, err
+	}
+	if res.Item == nil {
+		return nil, samplify.ErrRequiredFieldEmpty
+	}
+	report, err := c.GetDetailedLineItemReportWithContext(ctx, extProjectID, extLineItemID)
+	if err != nil {
+		return nil, err
+	}
+	return Rebalance(res.Item, &report.Report)
+}
+
+// ProposeRebalance reads a line item and its detailed report, and proposes a rebalanced quota plan.
+func ProposeRebalance(c *samplify.Client, extProjectID, extLineItemID string) (*Rebalancing, error) {
+	return ProposeRebalanceWithContext(context.Background(), c, extProjectID, extLineItemID)
+}
+
+// SubmitRebalanceWithContext updates the line item with the proposed quota plan.
+func SubmitRebalanceWithContext(ctx context.Context, c *samplify.Client, extProjectID string, r *Rebalancing) (*samplify.LineItemResponse, error) {
+	return c.UpdateLineItemWithContext(ctx, extProjectID, r.ExtLineItemID, r.UpdateCriteria())
+}
+
+// SubmitRebalance updates the line item with the proposed quota plan.
+func SubmitRebalance(c *samplify.Client, extProjectID string, r *Rebalancing) (*samplify.LineItemResponse, error) {
+	return SubmitRebalanceWithContext(context.Background(), c, extProjectID, r)
+}
+
+func toWeights(counts []uint32) []float64 {
+	weights := make([]float64, len(counts))
+	for i, c := range counts {
+		weights[i] = float64(c)
+	}
+	return weights
+}
@@ -0,0 +1,156 @@
+package quota_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+	"github.com/morningconsult/go-samplifyapi-client/lib/quota"
+)
+
+const rebalanceLineItemJSON = `{
+	"extLineItemId": "l1", "state": "LAUNCHED", "daysInField": 10,
+	"targets": [{"count": 100, "type": "COMPLETE"}],
+	"quotaPlan": {"quotaGroups": [
+		{"name": "Age", "quotaCells": [
+			{"quotaCellId": "a1", "count": 50, "quotaNodes": [{"attributeId": "13", "options": ["1"]}]},
+			{"quotaCellId": "a2", "count": 30, "quotaNodes": [{"attributeId": "13", "options": ["2"]}]},
+			{"quotaCellId": "a3", "count": 20, "quotaNodes": [{"attributeId": "13", "options": ["3"]}]}
+		]},
+		{"name": "Gender", "quotaCells": [
+			{"quotaCellId": "g1", "perc": 50, "quotaNodes": [{"attributeId": "11", "options": ["1"]}]},
+			{"quotaCellId": "g2", "perc": 50, "quotaNodes": [{"attributeId": "11", "options": ["2"]}]}
+		]}
+	]}
+}`
+
+func rebalanceReport(completes map[string]int64) *samplify.DetailedLineItemReport {
+	report := &samplify.DetailedLineItemReport{ExtLineItemID: "l1"}
+	group := &samplify.DetailedQuotaGroupReport{}
+	for _, id := range []string{"a1", "a2", "a3", "g1", "g2"} {
+		group.QuotaCells = append(group.QuotaCells, &samplify.DetailedQuotaCellReport{
+			QuotaCellID: id,
+			Stats:       samplify.DetailedStats{Completes: completes[id]},
+		})
+	}
+	report.QuotaGroups = []*samplify.DetailedQuotaGroupReport{group}
+	return report
+}
+
+func TestRebalance(t *testing.T) {
+	tables := []struct {
+		name      string
+		state     samplify.State
+		completes map[string]int64
+		expected  string
+		plan      string
+		err       error
+	}{
+		{
+			name:      "Case 1: over delivered cells keep their completes",
+			state:     samplify.StateLaunched,
+			completes: map[string]int64{"a1": 60, "a2": 10, "a3": 5, "g1": 60, "g2": 10},
+			expected:  "[a1:50/60/0->60 a2:30/10/20->24 a3:20/5/15->16 g1:50/60/0->60 g2:50/10/40->40]",
+			plan:      "[[[13[1]]=60 [13[2]]=24 [13[3]]=16] [[11[1]]=60 [11[2]]=40]]",
+		},
+		{
+			name:      "Case 2: on track",
+			state:     samplify.StatePaused,
+			completes: map[string]int64{"a1": 25, "a2": 15, "a3": 10, "g1": 25, "g2": 25},
+			expected:  "[a1:50/25/25->50 a2:30/15/15->30 a3:20/10/10->20 g1:50/25/25->50 g2:50/25/25->50]",
+			plan:      "[[[13[1]]=50 [13[2]]=30 [13[3]]=20] [[11[1]]=50 [11[2]]=50]]",
+		},
+		{
+			name:      "Case 3: more completes than the target",
+			state:     samplify.StateLaunched,
+			completes: map[string]int64{"a1": 90, "a2": 30},
+			err:       quota.ErrOverDelivered,
+		},
+		{
+			name:  "Case 4: closed line item",
+			state: samplify.StateClosed,
+			err:   quota.ErrNotRebalanceable,
+		},
+	}
+
+	for _, table := range tables {
+		var l samplify.LineItem
+		if err := json.Unmarshal([]byte(rebalanceLineItemJSON), &l); err != nil {
+			t.Fatal(err)
+		}
+		l.State = table.state
+		r, err := quota.Rebalance(&l, rebalanceReport(table.completes))
+		if !errors.Is(err, table.err) {
+			t.Errorf("%s: got error %v, want %v", table.name, err, table.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		var cells, groups []string
+		for _, c := range r.Cells {
+			cells = append(cells, fmt.Sprintf("%s:%d/%d/%d->%d", c.QuotaCellID, c.Planned, c.Delivered, c.Remaining, c.Proposed))
+		}
+		for _, g := range r.Plan.QuotaGroups {
+			groups = append(groups, describeGroup(g))
+		}
+		if got := fmt.Sprint(cells); got != table.expected {
+			t.Errorf("%s: got cells %s, want %s", table.name, got, table.expected)
+		}
+		if got := fmt.Sprint(groups); got != table.plan {
+			t.Errorf("%s: got plan %s, want %s", table.name, got, table.plan)
+		}
+		if r.Changed() != (table.expected != tables[1].expected) {
+			t.Errorf("%s: got Changed() %v", table.name, r.Changed())
+		}
+		if err := quota.ValidateTotals(r.Plan, r.Target); err != nil {
+			t.Errorf("%s: %v", table.name, err)
+		}
+		criteria := r.UpdateCriteria()
+		if criteria.ExtLineItemID != "l1" || criteria.DaysInField == nil || *criteria.DaysInField != 10 || criteria.QuotaPlan == r.Plan {
+			t.Errorf("%s: unexpected criteria %+v", table.name, criteria)
+		}
+	}
+}
+
+func TestRebalanceUpdateCriteriaSchedule(t *testing.T) {
+	tables := []struct {
+		name     string
+		days     int64
+		start    time.Time
+		schedule bool
+		expected int64
+	}{
+		{"not started", 0, time.Now().Add(time.Hour), true, 0},
+		{"started", 6, time.Now().Add(-time.Hour), true, 0},
+		{"no schedule", 6, time.Time{}, false, 6},
+	}
+	for _, table := range tables {
+		var l samplify.LineItem
+		if err := json.Unmarshal([]byte(rebalanceLineItemJSON), &l); err != nil {
+			t.Fatal(err)
+		}
+		l.DaysInField, l.FieldSchedule = table.days, nil
+		if !table.start.IsZero() {
+			l.FieldSchedule = samplify.NewSchedule(table.start, 4)
+		}
+		r, err := quota.Rebalance(&l, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		criteria := r.UpdateCriteria()
+		if (criteria.FieldSchedule != nil) != table.schedule || (table.schedule && !criteria.FieldSchedule.Equal(l.FieldSchedule)) {
+			t.Errorf("%s: got schedule %v", table.name, criteria.FieldSchedule)
+		}
+		var days int64
+		if criteria.DaysInField != nil {
+			days = *criteria.DaysInField
+		}
+		if days != table.expected {
+			t.Errorf("%s: got %d days in field, want %d", table.name, days, table.expected)
+		}
+	}
+}
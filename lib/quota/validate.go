@@ -0,0 +1,231 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+// Errors reported by Validate, in addition to those of the Builder
+var (
+	ErrUnknownOperator    = errors.New("unknown operator")
+	ErrDuplicateOption    = errors.New("option is listed more than once")
+	ErrDuplicateCell      = errors.New("quota cell has the same nodes as another cell of the group")
+	ErrExcludedAttributes = errors.New("attributes cannot be combined")
+)
+
+// Problem is an issue found by Validate, at the JSON path of the offending value in the line item.
+type Problem struct {
+	Path string
+	Err  error
+}
+
+func (p *Problem) Error() string {
+	return fmt.Sprintf("%s: %v", p.Path, p.Err)
+}
+
+func (p *Problem) Unwrap() error {
+	return p.Err
+}
+
+// ValidationError holds every problem found in a quota plan.
+type ValidationError struct {
+	Problems []*Problem
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		msgs = append(msgs, p.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Has tells whether one of the problems matches target with errors.Is.
+func (e *ValidationError) Has(target error) bool {
+	for _, p := range e.Problems {
+		if errors.Is(p.Err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks a quota plan against the attributes of the line item's country and language: attributes and
+// options must exist and be active, attributes must be allowed in filters or quotas, operators must be valid,
+// excluded attributes must not be combined, and cells must have a consistent allocation and be unique in their
+// group. It returns a *ValidationError listing every problem, or nil.
+func Validate(plan *samplify.QuotaPlan, attributes []*samplify.Attribute) error {
+	if plan == nil {
+		return nil
+	}
+	v := &validator{attributes: attributes, used: make(map[string]int)}
+	for i, f := range plan.Filters {
+		path := fmt.Sprintf("quotaPlan.filters[%d]", i)
+		a := v.node(path, f.AttributeID, f.Options)
+		if a != nil && !a.IsAllowedInFilters {
+			v.add(path+".attributeId", fmt.Errorf("%w: %s", ErrNotAllowedInFilters, a.Name))
+		}
+		if f.Operator != nil && *f.Operator != samplify.OperatorInclude && *f.Operator != samplify.OperatorExclude {
+			v.add(path+".operator", fmt.Errorf("%w: %q", ErrUnknownOperator, *f.Operator))
+		}
+	}
+	for i, g := range plan.QuotaGroups {
+		path := fmt.Sprintf("quotaPlan.quotaGroups[%d]", i)
+		if len(g.QuotaCells) == 0 {
+			v.add(path+".quotaCells", samplify.ErrMissingQuotaCells)
+			continue
+		}
+		cells := make(map[string]int)
+		for j, c := range g.QuotaCells {
+			cellPath := fmt.Sprintf("%s.quotaCells[%d]", path, j)
+			switch {
+			case c.Perc == nil && c.Count == nil:
+				v.add(cellPath, samplify.ErrAllocationNotProvided)
+			case c.Perc != nil && c.Count != nil:
+				v.add(cellPath, samplify.ErrAmbigiuosAllocation)
+			case c.AllocationType() != g.QuotaCells[0].AllocationType():
+				v.add(cellPath, samplify.ErrInconsistentAllocationType)
+			}
+			if len(c.QuotaNodes) == 0 {
+				v.add(cellPath+".quotaNodes", fmt.Errorf("%w: the cell has no node", samplify.ErrRequiredFieldEmpty))
+			}
+			for k, n := range c.QuotaNodes {
+				nodePath := fmt.Sprintf("%s.quotaNodes[%d]", cellPath, k)
+				a := v.node(nodePath, n.AttributeID, n.Options)
+				if a != nil && !a.IsAllowedInQuotas {
+					v.add(nodePath+".attributeId", fmt.Errorf("%w: %s", ErrNotAllowedInQuotas, a.Name))
+				}
+			}
+			key := cellKey(c)
+			if first, ok := cells[key]; ok && len(c.QuotaNodes) > 0 {
+				v.add(cellPath, fmt.Errorf("%w: quotaCells[%d]", ErrDuplicateCell, first))
+				continue
+			}
+			cells[key] = j
+		}
+	}
+	v.exclusions()
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+// ValidateForWithContext checks a quota plan against the attributes available for the country and language.
+func ValidateForWithContext(ctx context.Context, c *samplify.Client, countryCode, languageCode string, plan *samplify.QuotaPlan) error {
+	attributes, err := c.AllAttributesWithContext(ctx, countryCode, languageCode)
+	if err != nil {
+		return err
+	}
+	return Validate(plan, attributes)
+}
+
+// ValidateFor checks a quota plan against the attributes available for the country and language.
+func ValidateFor(c *samplify.Client, countryCode, languageCode string, plan *samplify.QuotaPlan) error {
+	return ValidateForWithContext(context.Background(), c, countryCode, languageCode, plan)
+}
+
+type validator struct {
+	attributes []*samplify.Attribute
+	// used maps the attributes of the plan to their order of first use, and paths holds the path of that use.
+	used     map[string]int
+	order    []string
+	paths    []string
+	problems []*Problem
+}
+
+func (v *validator) add(path string, err error) {
+	v.problems = append(v.problems, &Problem{Path: path, Err: err})
+}
+
+// node checks the attribute and options of a filter or node, and returns the attribute if it exists.
+func (v *validator) node(path, attributeID string, options []string) *samplify.Attribute {
+	var a *samplify.Attribute
+	for _, attr := range v.attributes {
+		if attr.ID == attributeID {
+			a = attr
+			break
+		}
+	}
+	if a == nil {
+		v.add(path+".attributeId", fmt.Errorf("%w: %q", ErrUnknownAttribute, attributeID))
+		return nil
+	}
+	if _, ok := v.used[a.ID]; !ok {
+		v.used[a.ID] = len(v.order)
+		v.order = append(v.order, a.ID)
+		v.paths = append(v.paths, path+".attributeId")
+	}
+	if a.State == samplify.StateDeprecated || a.State == samplify.StateInactive {
+		v.add(path+".attributeId", fmt.Errorf("%w: %s is %s", ErrAttributeNotActive, a.Name, a.State))
+	}
+	if len(options) == 0 {
+		v.add(path+".options", fmt.Errorf("%w: %s", ErrNoOptions, a.Name))
+	}
+	seen := make(map[string]bool, len(options))
+	for i, o := range options {
+		optionPath := fmt.Sprintf("%s.options[%d]", path, i)
+		switch {
+		case seen[o]:
+			v.add(optionPath, fmt.Errorf("%w: %q", ErrDuplicateOption, o))
+		case !hasOption(a, o):
+			v.add(optionPath, fmt.Errorf("%w: %q of %s", ErrUnknownOption, o, a.Name))
+		}
+		seen[o] = true
+	}
+	return a
+}
+
+// exclusions reports the pairs of attributes of the plan that exclude each other, once per pair.
+func (v *validator) exclusions() {
+	reported := make(map[string]bool)
+	for i, id := range v.order {
+		a := FindAttribute(v.attributes, id)
+		for _, e := range a.Exclusions {
+			if e == nil {
+				continue
+			}
+			j, ok := v.used[*e]
+			if !ok || j == i {
+				continue
+			}
+			first, last := i, j
+			if first > last {
+				first, last = last, first
+			}
+			key := fmt.Sprintf("%d-%d", first, last)
+			if reported[key] {
+				continue
+			}
+			reported[key] = true
+			v.add(v.paths[last], fmt.Errorf("%w: %s and %s", ErrExcludedAttributes,
+				FindAttribute(v.attributes, v.order[first]).Name, FindAttribute(v.attributes, v.order[last]).Name))
+		}
+	}
+}
+
+func hasOption(a *samplify.Attribute, id string) bool {
+	for _, o := range a.Options {
+		if o.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// cellKey identifies the respondents of a cell, whatever the order of its nodes and options.
+func cellKey(c *samplify.QuotaCell) string {
+	nodes := make([]string, 0, len(c.QuotaNodes))
+	for _, n := range c.QuotaNodes {
+		options := append([]string{}, n.Options...)
+		sort.Strings(options)
+		nodes = append(nodes, n.AttributeID+"="+strings.Join(options, ","))
+	}
+	sort.Strings(nodes)
+	return strings.Join(nodes, ";")
+}
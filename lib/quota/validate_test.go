@@ -0,0 +1,118 @@
+package quota_test
+
+import (
+	"errors"
+	"testing"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+	"github.com/morningconsult/go-samplifyapi-client/lib/quota"
+)
+
+func TestValidate(t *testing.T) {
+	perc50, perc100 := 50.0, 100.0
+	count := uint32(10)
+	include, unknown := samplify.OperatorInclude, samplify.Operator("between")
+	node := func(attributeID string, options ...string) []*samplify.QuotaNode {
+		return []*samplify.QuotaNode{{AttributeID: attributeID, Options: options}}
+	}
+
+	tables := []struct {
+		name     string
+		plan     *samplify.QuotaPlan
+		excludes bool
+		paths    []string
+		errs     []error
+	}{
+		{
+			name: "Case 1: valid plan",
+			plan: &samplify.QuotaPlan{
+				Filters: []*samplify.QuotaFilters{{AttributeID: "61961", Options: []string{"1"}, Operator: &include}},
+				QuotaGroups: []*samplify.QuotaGroup{{QuotaCells: []*samplify.QuotaCell{
+					{Perc: &perc50, QuotaNodes: node("11", "1")},
+					{Perc: &perc50, QuotaNodes: node("11", "2")},
+				}}},
+			},
+		},
+		{
+			name: "Case 2: every problem is reported with its path",
+			plan: &samplify.QuotaPlan{
+				Filters: []*samplify.QuotaFilters{
+					{AttributeID: "404", Options: []string{"1"}},
+					{AttributeID: "11", Options: []string{"1", "1", "9"}, Operator: &unknown},
+				},
+				QuotaGroups: []*samplify.QuotaGroup{
+					{QuotaCells: []*samplify.QuotaCell{
+						{Perc: &perc100, QuotaNodes: node("61961", "1")},
+						{Count: &count, QuotaNodes: node("4091", "3")},
+						{Perc: &perc100, QuotaNodes: node("61961", "1")},
+					}},
+					{},
+				},
+			},
+			paths: []string{
+				"quotaPlan.filters[0].attributeId",
+				"quotaPlan.filters[1].options[1]",
+				"quotaPlan.filters[1].options[2]",
+				"quotaPlan.filters[1].operator",
+				"quotaPlan.quotaGroups[0].quotaCells[0].quotaNodes[0].attributeId",
+				"quotaPlan.quotaGroups[0].quotaCells[1]",
+				"quotaPlan.quotaGroups[0].quotaCells[1].quotaNodes[0].attributeId",
+				"quotaPlan.quotaGroups[0].quotaCells[2].quotaNodes[0].attributeId",
+				"quotaPlan.quotaGroups[0].quotaCells[2]",
+				"quotaPlan.quotaGroups[1].quotaCells",
+			},
+			errs: []error{
+				quota.ErrUnknownAttribute,
+				quota.ErrDuplicateOption,
+				quota.ErrUnknownOption,
+				quota.ErrUnknownOperator,
+				quota.ErrNotAllowedInQuotas,
+				samplify.ErrInconsistentAllocationType,
+				quota.ErrAttributeNotActive,
+				quota.ErrNotAllowedInQuotas,
+				quota.ErrDuplicateCell,
+				samplify.ErrMissingQuotaCells,
+			},
+		},
+		{
+			name: "Case 3: excluded attributes are reported once",
+			plan: &samplify.QuotaPlan{QuotaGroups: []*samplify.QuotaGroup{
+				{QuotaCells: []*samplify.QuotaCell{{Perc: &perc100, QuotaNodes: node("11", "1", "2")}}},
+				{QuotaCells: []*samplify.QuotaCell{{Perc: &perc100, QuotaNodes: node("13", "1")}}},
+			}},
+			excludes: true,
+			paths:    []string{"quotaPlan.quotaGroups[1].quotaCells[0].quotaNodes[0].attributeId"},
+			errs:     []error{quota.ErrExcludedAttributes},
+		},
+	}
+
+	for _, table := range tables {
+		attributes := testAttributes(t)
+		if table.excludes {
+			gender, age := attributes[0], attributes[1]
+			gender.Exclusions = []*string{&age.ID}
+			age.Exclusions = []*string{&gender.ID}
+		}
+		err := quota.Validate(table.plan, attributes)
+		if len(table.paths) == 0 {
+			if err != nil {
+				t.Errorf("%s: got error %v", table.name, err)
+			}
+			continue
+		}
+		var verr *quota.ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("%s: got error %v, want a *ValidationError", table.name, err)
+			continue
+		}
+		if len(verr.Problems) != len(table.paths) {
+			t.Errorf("%s: got %d problems, want %d:\n%v", table.name, len(verr.Problems), len(table.paths), err)
+			continue
+		}
+		for i, p := range verr.Problems {
+			if p.Path != table.paths[i] || !errors.Is(p, table.errs[i]) {
+				t.Errorf("%s: problem %d: got %v, want %s: %v", table.name, i, p, table.paths[i], table.errs[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// Package reconcile builds and submits Request correction files for the Samplify API.
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+// Validation errors
+var (
+	ErrNoRows              = errors.New("the correction file has no rows")
+	ErrInvalidRespondentID = errors.New("invalid respondent id")
+	ErrDuplicateRespondent = errors.New("respondent id is listed more than once")
+	ErrMissingDisposition  = errors.New("the disposition is empty")
+	ErrWrongProject        = errors.New("respondent belongs to a different project")
+	ErrMissingExtProjectID = errors.New("the correction file has no project")
+)
+
+var (
+	respondentIDPattern = regexp.MustCompile(`^[A-Za-z0-9_\-]{1,128}$`)
+	csvHeader           = []string{"Respondent ID", "Disposition"}
+)
+
+// Row is a single respondent correction. Disposition is the status the respondent should be corrected to, written as
+// is in the file.
+type Row struct {
+	RespondentID string
+	Disposition  string
+	// ExtProjectID is optional. When set it must match the project of the file.
+	ExtProjectID string
+}
+
+// Problem describes why a row failed validation. Line is the 1-based position of the row in the file, excluding the header.
+type Problem struct {
+	Line         int
+	RespondentID string
+	Err          error
+}
+
+func (p *Problem) Error() string {
+	return fmt.Sprintf("line %d (%s): %v", p.Line, p.RespondentID, p.Err)
+}
+
+// ValidationError holds every problem found in a correction file.
+type ValidationError struct {
+	Problems []*Problem
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		msgs = append(msgs, p.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// File is a Request correction file for a single project.
+type File struct {
+	ExtProjectID string
+	Rows         []*Row
+}
+
+// NewFile returns an empty correction file for the project.
+func NewFile(extProjectID string) *File {
+	return &File{ExtProjectID: extProjectID}
+}
+
+// Add appends a correction for the respondent to the file.
+func (f *File) Add(respondentID, disposition string) *File {
+	f.Rows = append(f.Rows, &Row{RespondentID: respondentID, Disposition: disposition})
+	return f
+}
+
+// AddAll appends the same correction for every respondent to the file.
+func (f *File) AddAll(respondentIDs []string, disposition string) *File {
+	for _, id := range respondentIDs {
+		f.Add(id, disposition)
+	}
+	return f
+}
+
+// Validate checks the file locally, without calling the API. It returns a *ValidationError listing every problem found.
+func (f *File) Validate() error {
+	if len(strings.TrimSpace(f.ExtProjectID)) == 0 {
+		return ErrMissingExtProjectID
+	}
+	if len(f.Rows) == 0 {
+		return ErrNoRows
+	}
+	var problems []*Problem
+	seen := make(map[string]bool, len(f.Rows))
+	for i, r := range f.Rows {
+		add := func(err error) {
+			problems = append(problems, &Problem{Line: i + 1, RespondentID: r.RespondentID, Err: err})
+		}
+		if !respondentIDPattern.MatchString(r.RespondentID) {
+			add(ErrInvalidRespondentID)
+		} else if seen[r.RespondentID] {
+			add(ErrDuplicateRespondent)
+		}
+		seen[r.RespondentID] = true
+		if len(strings.TrimSpace(r.Disposition)) == 0 {
+			add(ErrMissingDisposition)
+		}
+		if len(r.ExtProjectID) > 0 && r.ExtProjectID != f.ExtProjectID {
+			add(ErrWrongProject)
+		}
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// WriteCSV validates the file and writes it as CSV. The output starts with a UTF-8 byte order mark and uses CRLF line
+// endings so that it opens correctly in spreadsheet applications such as Excel.
+func (f *File) WriteCSV(w io.Writer) error {
+	err := f.Validate()
+	if err != nil {
+		return err
+	}
+	err = samplify.WriteByteOrderMark(w)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	cw.UseCRLF = true
+	err = cw.Write(csvHeader)
+	if err != nil {
+		return err
+	}
+	for _, r := range f.Rows {
+		err = cw.Write([]string{r.RespondentID, r.Disposition})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// FileName returns the name the file is uploaded with.
+func (f *File) FileName() string {
+	return fmt.Sprintf("%s-reconcile.csv", f.ExtProjectID)
+}
+
+// SubmitWithContext validates the file and uploads it to the project using the client.
+func (f *File) SubmitWithContext(ctx context.Context, c *samplify.Client, message string) (*samplify.ReconcileResponse, error) {
+	buf := &bytes.Buffer{}
+	err := f.WriteCSV(buf)
+	if err != nil {
+		return nil, err
+	}
+	return c.UploadReconcileFromReaderWithContext(ctx, f.ExtProjectID, bytes.NewReader(buf.Bytes()), f.FileName(), message, nil)
+}
+
+// Submit validates the file and uploads it to the project using the client.
+func (f *File) Submit(c *samplify.Client, message string) (*samplify.ReconcileResponse, error) {
+	return f.SubmitWithContext(context.Background(), c, message)
+}
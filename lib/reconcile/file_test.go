@@ -0,0 +1,117 @@
+package reconcile_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+	"github.com/morningconsult/go-samplifyapi-client/lib/reconcile"
+)
+
+func TestValidate(t *testing.T) {
+	tables := []struct {
+		name     string
+		file     *reconcile.File
+		expected []error
+	}{
+		{
+			"Case 1: Happy path",
+			reconcile.NewFile("prj").Add("r1", "COMPLETE").Add("r2", "REJECTED"),
+			nil,
+		},
+		{
+			"Case 2: Duplicate respondent",
+			reconcile.NewFile("prj").Add("r1", "COMPLETE").Add("r1", "REJECTED"),
+			[]error{reconcile.ErrDuplicateRespondent},
+		},
+		{
+			"Case 3: Missing disposition and invalid id",
+			reconcile.NewFile("prj").Add("r 1", " "),
+			[]error{reconcile.ErrInvalidRespondentID, reconcile.ErrMissingDisposition},
+		},
+		{
+			"Case 4: Wrong project",
+			&reconcile.File{ExtProjectID: "prj", Rows: []*reconcile.Row{
+				{RespondentID: "r1", Disposition: "SCREENOUT", ExtProjectID: "other"},
+			}},
+			[]error{reconcile.ErrWrongProject},
+		},
+	}
+
+	for _, table := range tables {
+		err := table.file.Validate()
+		if table.expected == nil {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", table.name, err)
+			}
+			continue
+		}
+		verr, ok := err.(*reconcile.ValidationError)
+		if !ok {
+			t.Fatalf("%s: expected a validation error, got %v", table.name, err)
+		}
+		if len(verr.Problems) != len(table.expected) {
+			t.Fatalf("%s: got %d problems, want %d", table.name, len(verr.Problems), len(table.expected))
+		}
+		for i, p := range verr.Problems {
+			if p.Err != table.expected[i] {
+				t.Errorf("%s: got `%v`, want `%v`", table.name, p.Err, table.expected[i])
+			}
+		}
+	}
+
+	if err := reconcile.NewFile("prj").Validate(); err != reconcile.ErrNoRows {
+		t.Errorf("expected ErrNoRows, got %v", err)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	buf := &bytes.Buffer{}
+	err := reconcile.NewFile("prj").AddAll([]string{"r1", "r2"}, "OVERQUOTA").WriteCSV(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "\xEF\xBB\xBFRespondent ID,Disposition\r\nr1,OVERQUOTA\r\nr2,OVERQUOTA\r\n"
+	if buf.String() != expected {
+		t.Errorf("got %q, want %q", buf.String(), expected)
+	}
+}
+
+func TestSubmit(t *testing.T) {
+	var path, fileName string
+	var content []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		f, h, err := r.FormFile("file")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fileName = h.Filename
+		content, _ = ioutil.ReadAll(f)
+		w.Write([]byte(`{"data":{"accepted":1,"rejected":0},"status":{"message":"success"}}`))
+	}))
+	defer ts.Close()
+
+	now := time.Now()
+	client := samplify.NewClient("", "", "", &samplify.ClientOptions{APIBaseURL: ts.URL, AuthURL: ts.URL})
+	client.Auth = samplify.TokenResponse{AccessToken: "test", Acquired: &now, ExpiresIn: 1800}
+
+	res, err := reconcile.NewFile("prj").Add("r1", "COMPLETE").Submit(client, "fix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/projects/prj/reconcile" || fileName != "prj-reconcile.csv" {
+		t.Errorf("unexpected upload %s %s", path, fileName)
+	}
+	if !bytes.Contains(content, []byte("r1,COMPLETE")) {
+		t.Errorf("unexpected file content %q", content)
+	}
+	if res.Result.Accepted != 1 {
+		t.Errorf("unexpected result %+v", res.Result)
+	}
+}
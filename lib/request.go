@@ -1,6 +1,7 @@
 package samplify
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -12,6 +13,25 @@ import (
 	"time"
 )
 
+// utf8ByteOrderMark starts the CSV files exchanged with the API, for spreadsheet applications to read them as UTF-8.
+const utf8ByteOrderMark = "\xEF\xBB\xBF"
+
+// WriteByteOrderMark writes the UTF-8 byte order mark that starts the CSV files exchanged with the API.
+func WriteByteOrderMark(w io.Writer) error {
+	_, err := io.WriteString(w, utf8ByteOrderMark)
+	return err
+}
+
+// SkipByteOrderMark discards the UTF-8 byte order mark at the start of r, if there is one.
+func SkipByteOrderMark(r *bufio.Reader) error {
+	b, err := r.Peek(len(utf8ByteOrderMark))
+	if err != nil || string(b) != utf8ByteOrderMark {
+		return nil
+	}
+	_, err = r.Discard(len(utf8ByteOrderMark))
+	return err
+}
+
 // APIResponse ...
 type APIResponse struct {
 	Body      json.RawMessage
@@ -79,28 +99,29 @@ func (c *Client) sendRequest(ctx context.Context, host, method, url, accessToken
 	return ar, err
 }
 
-func (c *Client) sendFormData(ctx context.Context, host, method, path, accessToken string, file multipart.File, fileName string, message string) (*APIResponse, error) {
+func (c *Client) sendFormData(ctx context.Context, host, method, path, accessToken string, file io.Reader, fileName string, message string) (*APIResponse, error) {
 	// log.WithFields(log.Fields{"module": "go-samplifyapi-client", "function": "sendFormData", "URL": fmt.Sprintf("%s%s", host, path), "Method": method}).Info()
-	bodyBuf := &bytes.Buffer{}
-	bodyWriter := multipart.NewWriter(bodyBuf)
-	fileWriter, err := bodyWriter.CreateFormFile("file", fileName)
-	if err != nil {
-		// log.WithFields(log.Fields{"module": "go-samplifyapi-client", "function": "sendFormData", "Method": method}).Error(err)
-		return nil, err
-	}
-	_, err = io.Copy(fileWriter, file)
-	if err != nil {
-		return nil, err
-	}
-	bodyWriter.WriteField("message", message)
-	bodyWriter.Close()
-	req, err := http.NewRequest(method, fmt.Sprintf("%s%s", host, path), bodyBuf)
+	// The multipart body is streamed through a pipe so that the file is never held in memory as a whole.
+	pr, pw := io.Pipe()
+	bodyWriter := multipart.NewWriter(pw)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pw.CloseWithError(writeFormData(bodyWriter, file, fileName, message))
+	}()
+	// Wait for the writer to stop touching the file before returning, callers may rewind it.
+	defer func() {
+		pr.Close()
+		<-done
+	}()
+	req, err := http.NewRequest(method, fmt.Sprintf("%s%s", host, path), pr)
 	if err != nil {
 		return nil, err
 	}
 	if len(accessToken) > 0 {
 		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", accessToken))
 	}
+	req.Header.Add("Accept", "application/json")
 	req.Header.Add("Content-Type", bodyWriter.FormDataContentType())
 	req = req.WithContext(ctx)
 	resp, err := c.HTTPClient.Do(req)
@@ -135,3 +156,20 @@ func (c *Client) sendFormData(ctx context.Context, host, method, path, accessTok
 	ar.Body = json.RawMessage(bodyjson)
 	return ar, err
 }
+
+// writeFormData writes the file part followed by the message field and closes the multipart writer.
+func writeFormData(bodyWriter *multipart.Writer, file io.Reader, fileName string, message string) error {
+	fileWriter, err := bodyWriter.CreateFormFile("file", fileName)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(fileWriter, file)
+	if err != nil {
+		return err
+	}
+	err = bodyWriter.WriteField("message", message)
+	if err != nil {
+		return err
+	}
+	return bodyWriter.Close()
+}
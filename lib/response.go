@@ -147,6 +147,19 @@ type QuotaCellResponse struct {
 	Meta           Meta           `json:"meta"`
 }
 
+// GetInvoiceSummariesResponse ...
+type GetInvoiceSummariesResponse struct {
+	List           []*InvoiceSummary `json:"data"`
+	ResponseStatus ResponseStatus    `json:"status"`
+	Meta           Meta              `json:"meta"`
+}
+
+// ReconcileResponse ... Response returned by the Request correction file upload
+type ReconcileResponse struct {
+	Result         *ReconcileResult `json:"data"`
+	ResponseStatus ResponseStatus   `json:"status"`
+}
+
 // ResponseStatus is the custom status part in API response. (Optional in some endpoints)
 type ResponseStatus struct {
 	Message string      `json:"message"`
@@ -0,0 +1,332 @@
+// Package rollout adds the same line item to a project for several markets, translating its quota plan to the
+// attributes of each market.
+package rollout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+// Errors returned by a Rollout
+var (
+	ErrNoMarkets       = errors.New("at least one market must be given")
+	ErrMissingTemplate = errors.New("a line item template is required")
+)
+
+// IssueKind is the kind of problem that keeps a line item from being rolled out to a market.
+type IssueKind string
+
+// IssueKind values
+const (
+	IssueUnsupportedCountry  IssueKind = "unsupportedCountry"
+	IssueUnsupportedLanguage IssueKind = "unsupportedLanguage"
+	IssueMissingAttribute    IssueKind = "missingAttribute"
+	IssueMissingOption       IssueKind = "missingOption"
+)
+
+// Market is a country and language to roll the template out to. ExtLineItemID and Title default to those of the
+// template, suffixed with the country and language.
+type Market struct {
+	CountryISOCode  string
+	LanguageISOCode string
+	ExtLineItemID   string
+	Title           string
+}
+
+func (m Market) String() string {
+	return fmt.Sprintf("%s/%s", strings.ToUpper(m.CountryISOCode), strings.ToLower(m.LanguageISOCode))
+}
+
+// Issue is a problem found for a market. AttributeID and Option are those of the template.
+type Issue struct {
+	Market      Market
+	Kind        IssueKind
+	AttributeID string
+	Option      string
+	Reason      string
+}
+
+func (i *Issue) Error() string {
+	switch {
+	case len(i.Option) > 0:
+		return fmt.Sprintf("%s: %s %s=%s: %s", i.Market, i.Kind, i.AttributeID, i.Option, i.Reason)
+	case len(i.AttributeID) > 0:
+		return fmt.Sprintf("%s: %s %s: %s", i.Market, i.Kind, i.AttributeID, i.Reason)
+	}
+	return fmt.Sprintf("%s: %s: %s", i.Market, i.Kind, i.Reason)
+}
+
+// MarketResult is the line item of a market. Issues are the problems that keep it from being added, and Err the
+// error returned when adding it.
+type MarketResult struct {
+	Market   Market
+	LineItem *samplify.CreateLineItemCriteria
+	Issues   []*Issue
+	Added    bool
+	Err      error
+}
+
+// Result holds the outcome of every market, in the order they were given.
+type Result struct {
+	Markets []*MarketResult
+}
+
+// Issues returns the issues of every market.
+func (r *Result) Issues() []*Issue {
+	var res []*Issue
+	for _, m := range r.Markets {
+		res = append(res, m.Issues...)
+	}
+	return res
+}
+
+// Err returns an error listing the markets that were not added, or nil if there is none.
+func (r *Result) Err() error {
+	var msgs []string
+	for _, m := range r.Markets {
+		for _, i := range m.Issues {
+			msgs = append(msgs, i.Error())
+		}
+		if m.Err != nil {
+			msgs = append(msgs, fmt.Sprintf("%s: %v", m.Market, m.Err))
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("the line item could not be rolled out to every market:\n%s", strings.Join(msgs, "\n"))
+}
+
+// Rollout adds a line item built from Template to a project for every market. The quota plan of the template uses
+// the attributes of the template's country and language: each attribute is matched in a market by name, or else by
+// category and text, and each option by text. Sources and the other fields are copied as they are.
+type Rollout struct {
+	Client       *samplify.Client
+	ExtProjectID string
+	Template     *samplify.CreateLineItemCriteria
+	Markets      []Market
+}
+
+// PlanWithContext checks every market against GetCountries and translates the quota plan of the template, without
+// adding anything.
+func (r *Rollout) PlanWithContext(ctx context.Context) (*Result, error) {
+	if r.Template == nil {
+		return nil, ErrMissingTemplate
+	}
+	if len(r.Markets) == 0 {
+		return nil, ErrNoMarkets
+	}
+	countries, err := r.Client.AllCountriesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var source []*samplify.Attribute
+	if r.Template.QuotaPlan != nil {
+		source, err = r.Client.AllAttributesWithContext(ctx, r.Template.CountryISOCode, r.Template.LanguageISOCode)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := &Result{}
+	for _, m := range r.Markets {
+		mr := &MarketResult{Market: m, LineItem: r.lineItem(m)}
+		result.Markets = append(result.Markets, mr)
+		country, language, issue := checkMarket(countries, m)
+		if issue != nil {
+			mr.Issues = append(mr.Issues, issue)
+			continue
+		}
+		mr.LineItem.CountryISOCode, mr.LineItem.LanguageISOCode = country, language
+		if r.Template.QuotaPlan == nil {
+			continue
+		}
+		target, err := r.Client.AllAttributesWithContext(ctx, country, language)
+		if err != nil {
+			return nil, err
+		}
+		t := &translator{market: m, source: source, target: target}
+		mr.LineItem.QuotaPlan = t.plan(r.Template.QuotaPlan)
+		mr.Issues = t.issues
+	}
+	return result, nil
+}
+
+// Plan checks every market and translates the quota plan of the template, without adding anything.
+func (r *Rollout) Plan() (*Result, error) {
+	return r.PlanWithContext(context.Background())
+}
+
+// RunWithContext plans the rollout, then adds the line items of the markets without issues to the project. The
+// markets with issues are skipped; Result.Err lists them along with the line items that failed to be added.
+func (r *Rollout) RunWithContext(ctx context.Context) (*Result, error) {
+	result, err := r.PlanWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range result.Markets {
+		if len(m.Issues) > 0 {
+			continue
+		}
+		if _, m.Err = r.Client.AddLineItemWithContext(ctx, r.ExtProjectID, m.LineItem); m.Err == nil {
+			m.Added = true
+		}
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+	}
+	return result, nil
+}
+
+// Run plans the rollout, then adds the line items of the markets without issues to the project.
+func (r *Rollout) Run() (*Result, error) {
+	return r.RunWithContext(context.Background())
+}
+
+// lineItem returns a copy of the template for the market.
+func (r *Rollout) lineItem(m Market) *samplify.CreateLineItemCriteria {
+	l := *r.Template
+	suffix := strings.ToLower(m.CountryISOCode + "-" + m.LanguageISOCode)
+	l.ExtLineItemID = m.ExtLineItemID
+	if len(l.ExtLineItemID) == 0 {
+		l.ExtLineItemID = r.Template.ExtLineItemID + "-" + suffix
+	}
+	l.Title = m.Title
+	if len(l.Title) == 0 {
+		l.Title = fmt.Sprintf("%s %s", r.Template.Title, m)
+	}
+	l.CountryISOCode, l.LanguageISOCode = m.CountryISOCode, m.LanguageISOCode
+	l.QuotaPlan = r.Template.QuotaPlan.Clone()
+	l.Targets = nil
+	for _, t := range r.Template.Targets {
+		l.Targets = append(l.Targets, t.Clone())
+	}
+	return &l
+}
+
+// checkMarket returns the ISO codes of the country and language of the market as listed by GetCountries, or an issue
+// if either is not supported.
+func checkMarket(countries []*samplify.Country, m Market) (string, string, *Issue) {
+	for _, c := range countries {
+		if !strings.EqualFold(c.IsoCode, m.CountryISOCode) {
+			continue
+		}
+		for _, l := range c.SupportedLanguages {
+			if strings.EqualFold(l.IsoCode, m.LanguageISOCode) {
+				return c.IsoCode, l.IsoCode, nil
+			}
+		}
+		return "", "", &Issue{Market: m, Kind: IssueUnsupportedLanguage, Reason: fmt.Sprintf("%s is not supported in %s", m.LanguageISOCode, c.CountryName)}
+	}
+	return "", "", &Issue{Market: m, Kind: IssueUnsupportedCountry, Reason: fmt.Sprintf("%s is not supported", m.CountryISOCode)}
+}
+
+// translator maps the attributes of the template to those of a market.
+type translator struct {
+	market Market
+	source []*samplify.Attribute
+	target []*samplify.Attribute
+	issues []*Issue
+}
+
+func (t *translator) issue(kind IssueKind, attributeID, option, reason string) {
+	t.issues = append(t.issues, &Issue{Market: t.market, Kind: kind, AttributeID: attributeID, Option: option, Reason: reason})
+}
+
+// plan translates the ids of the filters and nodes of a copy of the plan, leaving unmatched ids unchanged.
+func (t *translator) plan(plan *samplify.QuotaPlan) *samplify.QuotaPlan {
+	res := plan.Clone()
+	for _, f := range res.Filters {
+		f.AttributeID, f.Options = t.attribute(f.AttributeID, f.Options)
+	}
+	for _, g := range res.QuotaGroups {
+		for _, c := range g.QuotaCells {
+			for _, n := range c.QuotaNodes {
+				n.AttributeID, n.Options = t.attribute(n.AttributeID, n.Options)
+			}
+		}
+	}
+	return res
+}
+
+func (t *translator) attribute(id string, options []string) (string, []string) {
+	src := findByID(t.source, id)
+	if src == nil {
+		t.issue(IssueMissingAttribute, id, "", "not found in the template's country and language")
+		return id, options
+	}
+	dst := t.match(src)
+	if dst == nil {
+		t.issue(IssueMissingAttribute, id, "", fmt.Sprintf("%s does not exist", src.Name))
+		return id, options
+	}
+	if dst.State == samplify.StateInactive {
+		t.issue(IssueMissingAttribute, id, "", fmt.Sprintf("%s is inactive", dst.Name))
+	}
+	mapped := make([]string, 0, len(options))
+	for _, o := range options {
+		option := matchOption(src, dst, o)
+		if option == nil {
+			t.issue(IssueMissingOption, id, o, fmt.Sprintf("not an option of %s", dst.Name))
+			mapped = append(mapped, o)
+			continue
+		}
+		mapped = append(mapped, option.ID)
+	}
+	return dst.ID, mapped
+}
+
+// match returns the attribute of the market with the same name, or else in the same category with the same text.
+func (t *translator) match(src *samplify.Attribute) *samplify.Attribute {
+	for _, a := range t.target {
+		if strings.EqualFold(a.Name, src.Name) {
+			return a
+		}
+	}
+	for _, a := range t.target {
+		if sameCategory(a.AttributeCategory, src.AttributeCategory) && strings.EqualFold(a.Text, src.Text) {
+			return a
+		}
+	}
+	return nil
+}
+
+// matchOption returns the option of dst with the text of the option of src, or else with the same id if the
+// attributes share their id.
+func matchOption(src, dst *samplify.Attribute, id string) *samplify.AttributeOption {
+	for _, so := range src.Options {
+		if so.ID != id {
+			continue
+		}
+		for _, o := range dst.Options {
+			if strings.EqualFold(o.Text, so.Text) {
+				return o
+			}
+		}
+	}
+	if src.ID == dst.ID {
+		for _, o := range dst.Options {
+			if o.ID == id {
+				return o
+			}
+		}
+	}
+	return nil
+}
+
+func sameCategory(a, b samplify.AttributeCategory) bool {
+	return len(a.MainCategory.ID) > 0 && a.MainCategory.ID == b.MainCategory.ID && a.SubCategory.ID == b.SubCategory.ID
+}
+
+func findByID(attributes []*samplify.Attribute, id string) *samplify.Attribute {
+	for _, a := range attributes {
+		if a.ID == id {
+			return a
+		}
+	}
+	return nil
+}
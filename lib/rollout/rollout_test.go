@@ -0,0 +1,197 @@
+package rollout_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+	"github.com/morningconsult/go-samplifyapi-client/lib/rollout"
+)
+
+const countriesJSON = `{"data": [
+	{"id": "US", "isoCode": "US", "countryName": "United States", "supportedLanguages": [{"id": "en", "isoCode": "en"}]},
+	{"id": "GB", "isoCode": "GB", "countryName": "United Kingdom", "supportedLanguages": [{"id": "en", "isoCode": "en"}]},
+	{"id": "DE", "isoCode": "DE", "countryName": "Germany", "supportedLanguages": [{"id": "de", "isoCode": "de"}]},
+	{"id": "FR", "isoCode": "FR", "countryName": "France", "supportedLanguages": [{"id": "fr", "isoCode": "fr"}]}
+]}`
+
+const (
+	genderJSON = `{"id": "11", "name": "GENDER", "text": "Gender", "state": "ACTIVE",
+		"options": [{"id": "1", "text": "Male"}, {"id": "2", "text": "Female"}]}`
+	employmentJSON = `{"id": "%s", "name": "%s", "text": "Employment status", "state": "ACTIVE",
+		"category": {"mainCategory": {"id": "c1"}, "subCategory": {"id": "c2"}},
+		"options": [{"id": "%s", "text": "Employed"}, {"id": "%s", "text": "Unemployed"}]}`
+)
+
+var attributesJSON = map[string]string{
+	"US/en": fmt.Sprintf(`{"data": [%s, %s]}`, genderJSON, fmt.Sprintf(employmentJSON, "211", "EMPLOYMENT", "1", "2")),
+	"GB/en": fmt.Sprintf(`{"data": [%s, %s]}`, genderJSON, fmt.Sprintf(employmentJSON, "311", "EMPLOYMENT_STATUS", "9", "8")),
+	"DE/de": `{"data": [{"id": "11", "name": "GENDER", "text": "Gender", "state": "ACTIVE",
+		"options": [{"id": "1", "text": "Male"}, {"id": "3", "text": "Diverse"}]}]}`,
+}
+
+type fakeAPI struct {
+	mu    sync.Mutex
+	added []*samplify.CreateLineItemCriteria
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.Method == "GET" && parts[0] == "countries":
+		fmt.Fprint(w, countriesJSON)
+	case r.Method == "GET" && parts[0] == "attributes" && len(parts) == 3:
+		res, ok := attributesJSON[parts[1]+"/"+parts[2]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, res)
+	case r.Method == "POST" && len(parts) == 3 && parts[2] == "lineItems":
+		var l samplify.CreateLineItemCriteria
+		json.NewDecoder(r.Body).Decode(&l)
+		f.added = append(f.added, &l)
+		fmt.Fprintf(w, `{"data": {"extLineItemId": %q}}`, l.ExtLineItemID)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newRollout(markets ...rollout.Market) (*rollout.Rollout, *fakeAPI, func()) {
+	api := &fakeAPI{}
+	ts := httptest.NewServer(api)
+	now := time.Now()
+	client := samplify.NewClient("", "", "", &samplify.ClientOptions{APIBaseURL: ts.URL, AuthURL: ts.URL})
+	client.Auth = samplify.TokenResponse{AccessToken: "test", Acquired: &now, ExpiresIn: 1800}
+
+	surveyURL := "www.mysurvey.com/live/survey"
+	count := uint32(100)
+	perc := 50.0
+	return &rollout.Rollout{
+		Client:       client,
+		ExtProjectID: "p1",
+		Template: &samplify.CreateLineItemCriteria{
+			ExtLineItemID:       "li",
+			Title:               "Tracker",
+			CountryISOCode:      "US",
+			LanguageISOCode:     "en",
+			SurveyURL:           &surveyURL,
+			IndicativeIncidence: 50,
+			DaysInField:         10,
+			LengthOfInterview:   10,
+			Targets:             []*samplify.LineItemTarget{{Count: &count, Type: samplify.TargetTypeComplete}},
+			QuotaPlan: &samplify.QuotaPlan{
+				Filters: []*samplify.QuotaFilters{{AttributeID: "211", Options: []string{"1"}}},
+				QuotaGroups: []*samplify.QuotaGroup{{QuotaCells: []*samplify.QuotaCell{
+					{QuotaNodes: []*samplify.QuotaNode{{AttributeID: "11", Options: []string{"1"}}}, Perc: &perc},
+					{QuotaNodes: []*samplify.QuotaNode{{AttributeID: "11", Options: []string{"2"}}}, Perc: &perc},
+				}}},
+			},
+		},
+		Markets: markets,
+	}, api, ts.Close
+}
+
+func describePlan(p *samplify.QuotaPlan) string {
+	var res []string
+	for _, f := range p.Filters {
+		res = append(res, fmt.Sprintf("%s%v", f.AttributeID, f.Options))
+	}
+	for _, g := range p.QuotaGroups {
+		for _, c := range g.QuotaCells {
+			for _, n := range c.QuotaNodes {
+				res = append(res, fmt.Sprintf("%s%v", n.AttributeID, n.Options))
+			}
+		}
+	}
+	return strings.Join(res, " ")
+}
+
+func TestRolloutRun(t *testing.T) {
+	r, api, stop := newRollout(
+		rollout.Market{CountryISOCode: "GB", LanguageISOCode: "en"},
+		rollout.Market{CountryISOCode: "DE", LanguageISOCode: "de", ExtLineItemID: "li-germany"},
+		rollout.Market{CountryISOCode: "FR", LanguageISOCode: "en"},
+		rollout.Market{CountryISOCode: "XX", LanguageISOCode: "en"},
+	)
+	defer stop()
+
+	res, err := r.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, m := range res.Markets {
+		got = append(got, fmt.Sprintf("%s %s %t", m.Market, m.LineItem.ExtLineItemID, m.Added))
+	}
+	expected := []string{"GB/en li-gb-en true", "DE/de li-germany false", "FR/en li-fr-en false", "XX/en li-xx-en false"}
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("got markets %v, want %v", got, expected)
+	}
+
+	if len(api.added) != 1 {
+		t.Fatalf("got %d line items added, want 1", len(api.added))
+	}
+	l := api.added[0]
+	if l.Title != "Tracker GB/en" || l.CountryISOCode != "GB" || l.LanguageISOCode != "en" {
+		t.Errorf("unexpected line item %s %s %s", l.Title, l.CountryISOCode, l.LanguageISOCode)
+	}
+	if p := describePlan(l.QuotaPlan); p != "311[9] 11[1] 11[2]" {
+		t.Errorf("got plan %s, want 311[9] 11[1] 11[2]", p)
+	}
+	if p := describePlan(r.Template.QuotaPlan); p != "211[1] 11[1] 11[2]" {
+		t.Errorf("the template plan was changed to %s", p)
+	}
+
+	var issues []string
+	for _, i := range res.Issues() {
+		issues = append(issues, fmt.Sprintf("%s %s %s %s", i.Market, i.Kind, i.AttributeID, i.Option))
+	}
+	expectedIssues := []string{
+		"DE/de missingAttribute 211 ",
+		"DE/de missingOption 11 2",
+		"FR/en unsupportedLanguage  ",
+		"XX/en unsupportedCountry  ",
+	}
+	if fmt.Sprint(issues) != fmt.Sprint(expectedIssues) {
+		t.Errorf("got issues %q, want %q", issues, expectedIssues)
+	}
+	if err := res.Err(); err == nil || !strings.Contains(err.Error(), "DE/de: missingOption 11=2: not an option of GENDER") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestRolloutPlan(t *testing.T) {
+	tests := []struct {
+		name     string
+		markets  []rollout.Market
+		expected error
+	}{
+		{name: "no markets", expected: rollout.ErrNoMarkets},
+		{name: "plan", markets: []rollout.Market{{CountryISOCode: "gb", LanguageISOCode: "EN"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, api, stop := newRollout(tt.markets...)
+			defer stop()
+			res, err := r.Plan()
+			if err != tt.expected {
+				t.Fatalf("got error %v, want %v", err, tt.expected)
+			}
+			if len(api.added) > 0 {
+				t.Errorf("got %d line items added by a plan", len(api.added))
+			}
+			if err == nil && res.Err() != nil {
+				t.Errorf("unexpected issues: %v", res.Err())
+			}
+		})
+	}
+}
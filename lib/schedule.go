@@ -0,0 +1,144 @@
+package samplify
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ScheduleLayout is the format of the field schedule times in the API: RFC 3339, with an explicit time zone offset.
+const ScheduleLayout = time.RFC3339
+
+// Schedule is the field period of a line item. The times keep their time zone, and are sent with its offset.
+type Schedule struct {
+	StartTime time.Time
+	EndTime   time.Time
+	// invalid is set when a time read from JSON is not in the ScheduleLayout. Such times are left zero.
+	invalid error
+}
+
+// NewSchedule returns the schedule starting at start and lasting daysInField calendar days in the time zone of start.
+func NewSchedule(start time.Time, daysInField int64) *Schedule {
+	return &Schedule{StartTime: start, EndTime: start.AddDate(0, 0, int(daysInField))}
+}
+
+// In returns the schedule with its times in the location.
+func (s *Schedule) In(loc *time.Location) *Schedule {
+	return &Schedule{StartTime: s.StartTime.In(loc), EndTime: s.EndTime.In(loc)}
+}
+
+// Equal tells whether both schedules start and end at the same instants, whatever their time zones. A nil schedule
+// is only equal to another nil schedule.
+func (s *Schedule) Equal(o *Schedule) bool {
+	if s == nil || o == nil {
+		return s == o
+	}
+	return s.StartTime.Equal(o.StartTime) && s.EndTime.Equal(o.EndTime)
+}
+
+// Days returns the number of calendar days the schedule spans in the time zone of its start, a started day counting
+// as a full day. Days made longer or shorter by daylight saving time still count as one.
+func (s *Schedule) Days() int64 {
+	if !s.StartTime.Before(s.EndTime) {
+		return 0
+	}
+	// Daylight saving time moves the end by an hour at most, so the days fully elapsed are a lower bound.
+	days := int(s.EndTime.Sub(s.StartTime).Hours()/24) - 1
+	if days < 0 {
+		days = 0
+	}
+	for s.StartTime.AddDate(0, 0, days).Before(s.EndTime) {
+		days++
+	}
+	return int64(days)
+}
+
+// Validate checks that both times are set and were read in the ScheduleLayout, that the start precedes the end, and
+// that the start is in the future.
+func (s *Schedule) Validate() error {
+	if err := s.validateTimes(); err != nil {
+		return err
+	}
+	return s.validateStart()
+}
+
+// validateTimes checks the schedule without regard to the current time.
+func (s *Schedule) validateTimes() error {
+	if s.invalid != nil {
+		return s.invalid
+	}
+	if s.StartTime.IsZero() || s.EndTime.IsZero() {
+		return ErrScheduleIncomplete
+	}
+	if !s.StartTime.Before(s.EndTime) {
+		return fmt.Errorf("%w: %s is not before %s", ErrScheduleOrder,
+			s.StartTime.Format(ScheduleLayout), s.EndTime.Format(ScheduleLayout))
+	}
+	return nil
+}
+
+// validateStart checks that a new schedule starts in the future.
+func (s *Schedule) validateStart() error {
+	if !s.StartTime.After(time.Now()) {
+		return fmt.Errorf("%w: %s", ErrSchedulePast, s.StartTime.Format(ScheduleLayout))
+	}
+	return nil
+}
+
+// started tells whether the schedule has a start that is not in the future.
+func (s *Schedule) started() bool {
+	return s != nil && !s.StartTime.IsZero() && !s.StartTime.After(time.Now())
+}
+
+// startOf returns the start of the schedule, or the zero time for a nil schedule.
+func (s *Schedule) startOf() time.Time {
+	if s == nil {
+		return time.Time{}
+	}
+	return s.StartTime
+}
+
+type scheduleJSON struct {
+	StartTime string `json:"startTime"`
+	EndTime   string `json:"endTime"`
+}
+
+// MarshalJSON ...
+func (s Schedule) MarshalJSON() ([]byte, error) {
+	return json.Marshal(scheduleJSON{StartTime: formatScheduleTime(s.StartTime), EndTime: formatScheduleTime(s.EndTime)})
+}
+
+// UnmarshalJSON reads the times leniently, so that a malformed time does not fail the whole response: it is left
+// zero and reported by Validate.
+func (s *Schedule) UnmarshalJSON(b []byte) error {
+	var v scheduleJSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	var startErr, endErr error
+	s.StartTime, startErr = parseScheduleTime(v.StartTime)
+	s.EndTime, endErr = parseScheduleTime(v.EndTime)
+	s.invalid = startErr
+	if s.invalid == nil {
+		s.invalid = endErr
+	}
+	return nil
+}
+
+func formatScheduleTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(ScheduleLayout)
+}
+
+func parseScheduleTime(s string) (time.Time, error) {
+	if len(s) == 0 {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(ScheduleLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrScheduleTime, s)
+	}
+	return t, nil
+}
@@ -0,0 +1,233 @@
+package samplify_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+func TestValidateSchedule(t *testing.T) {
+	start := time.Now().Add(24 * time.Hour)
+	days := func(d int64) *int64 { return &d }
+	tests := []struct {
+		name        string
+		daysInField *int64
+		schedule    *samplify.Schedule
+		expected    error
+	}{
+		{"days in field only", days(5), nil, nil},
+		{"schedule only", nil, samplify.NewSchedule(start, 5), nil},
+		{"both consistent", days(5), samplify.NewSchedule(start, 5), nil},
+		{"neither", days(0), nil, samplify.ErrInvalidScheduleEmpty},
+		{"both inconsistent", days(4), samplify.NewSchedule(start, 5), samplify.ErrScheduleDaysInField},
+		{"partial day", days(2), &samplify.Schedule{StartTime: start, EndTime: start.Add(30 * time.Hour)}, nil},
+		{"no end", nil, &samplify.Schedule{StartTime: start}, samplify.ErrScheduleIncomplete},
+		{"end before start", nil, &samplify.Schedule{StartTime: start, EndTime: start.Add(-time.Hour)}, samplify.ErrScheduleOrder},
+		{"started", nil, samplify.NewSchedule(time.Now().Add(-time.Hour), 5), samplify.ErrSchedulePast},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := samplify.ValidateSchedule(tt.daysInField, tt.schedule); !errors.Is(err, tt.expected) {
+				t.Errorf("got error %v, want %v", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestScheduleJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		err      error
+	}{
+		{"utc", `{"startTime":"2030-01-02T09:00:00Z","endTime":"2030-01-07T09:00:00Z"}`, "", nil},
+		{"offset", `{"startTime":"2030-01-02T09:00:00+01:00","endTime":"2030-01-07T09:00:00-05:00"}`, "", nil},
+		{"fraction", `{"startTime":"2030-01-02T09:00:00.000Z","endTime":"2030-01-07T09:00:00.000Z"}`,
+			`{"startTime":"2030-01-02T09:00:00Z","endTime":"2030-01-07T09:00:00Z"}`, nil},
+		{"empty", `{"startTime":"","endTime":""}`, "", nil},
+		{"no time zone", `{"startTime":"2030-01-02T09:00:00","endTime":"2030-01-07T09:00:00Z"}`, "", samplify.ErrScheduleTime},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s samplify.Schedule
+			if err := json.Unmarshal([]byte(tt.input), &s); err != nil {
+				t.Fatal(err)
+			}
+			if tt.err != nil {
+				if err := s.Validate(); !errors.Is(err, tt.err) {
+					t.Errorf("got validation error %v, want %v", err, tt.err)
+				}
+				return
+			}
+			b, err := json.Marshal(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := tt.expected
+			if len(expected) == 0 {
+				expected = tt.input
+			}
+			if string(b) != expected {
+				t.Errorf("got %s, want %s", b, expected)
+			}
+		})
+	}
+}
+
+func TestScheduleJSONLenient(t *testing.T) {
+	var res samplify.LineItemResponse
+	input := `{"data": {"extLineItemId": "li", "fieldSchedule": {"startTime": "2030-01-02 09:00", "endTime": "2030-01-07T09:00:00Z"}}}`
+	if err := json.Unmarshal([]byte(input), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Item == nil || res.Item.ExtLineItemID != "li" {
+		t.Fatalf("unexpected line item %+v", res.Item)
+	}
+	if err := res.Item.FieldSchedule.Validate(); !errors.Is(err, samplify.ErrScheduleTime) {
+		t.Errorf("got error %v, want %v", err, samplify.ErrScheduleTime)
+	}
+}
+
+func TestScheduleIn(t *testing.T) {
+	loc := time.FixedZone("EST", -5*3600)
+	s := samplify.NewSchedule(time.Date(2030, 3, 1, 14, 0, 0, 0, time.UTC), 3).In(loc)
+	b, _ := json.Marshal(s)
+	if expected := `{"startTime":"2030-03-01T09:00:00-05:00","endTime":"2030-03-04T09:00:00-05:00"}`; string(b) != expected {
+		t.Errorf("got %s, want %s", b, expected)
+	}
+	if s.Days() != 3 {
+		t.Errorf("got %d days, want 3", s.Days())
+	}
+}
+
+func TestScheduleDays(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	start := time.Date(2026, 10, 30, 9, 0, 0, 0, newYork)
+	tests := []struct {
+		name     string
+		schedule *samplify.Schedule
+		expected int64
+	}{
+		{"end of daylight saving time", samplify.NewSchedule(start, 5), 5},
+		{"start of daylight saving time", samplify.NewSchedule(time.Date(2026, 3, 6, 9, 0, 0, 0, newYork), 5), 5},
+		{"utc", samplify.NewSchedule(start.UTC(), 5), 5},
+		{"partial day", &samplify.Schedule{StartTime: start, EndTime: start.AddDate(0, 0, 2).Add(time.Minute)}, 3},
+		{"same day", &samplify.Schedule{StartTime: start, EndTime: start.Add(time.Hour)}, 1},
+		{"end before start", &samplify.Schedule{StartTime: start, EndTime: start.Add(-time.Hour)}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if days := tt.schedule.Days(); days != tt.expected {
+				t.Errorf("got %d days, want %d", days, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAddLineItemSchedule(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(`{"data": {}}`))
+	}))
+	defer ts.Close()
+	now := time.Now()
+	client := samplify.NewClient("", "", "", &samplify.ClientOptions{APIBaseURL: ts.URL, AuthURL: ts.URL})
+	client.Auth = samplify.TokenResponse{AccessToken: "test", Acquired: &now, ExpiresIn: 1800}
+
+	l := getLineItemCriteria()
+	l.FieldSchedule = samplify.NewSchedule(now.Add(-time.Hour), l.DaysInField)
+	if _, err := client.AddLineItem("test", l); !errors.Is(err, samplify.ErrSchedulePast) {
+		t.Errorf("got error %v, want %v", err, samplify.ErrSchedulePast)
+	}
+	days := int64(3)
+	u := &samplify.UpdateLineItemCriteria{DaysInField: &days, FieldSchedule: samplify.NewSchedule(now.Add(time.Hour), 4)}
+	if _, err := client.UpdateLineItem("test", "lineItem001", u); !errors.Is(err, samplify.ErrScheduleDaysInField) {
+		t.Errorf("got error %v, want %v", err, samplify.ErrScheduleDaysInField)
+	}
+	p := getProjectCriteria()
+	p.LineItems[0].FieldSchedule = samplify.NewSchedule(now.Add(time.Hour), p.LineItems[0].DaysInField+1)
+	if _, err := client.CreateProject(p); !errors.Is(err, samplify.ErrScheduleDaysInField) {
+		t.Errorf("got error %v, want %v", err, samplify.ErrScheduleDaysInField)
+	}
+	up := &samplify.UpdateProjectCriteria{ExtProjectID: "test", LineItems: &[]*samplify.UpdateLineItemCriteria{
+		{ExtLineItemID: "lineItem001", FieldSchedule: &samplify.Schedule{StartTime: now.Add(time.Hour)}},
+	}}
+	if _, err := client.UpdateProject(up); !errors.Is(err, samplify.ErrScheduleIncomplete) {
+		t.Errorf("got error %v, want %v", err, samplify.ErrScheduleIncomplete)
+	}
+	if calls != 0 {
+		t.Errorf("got %d calls to the API, want 0", calls)
+	}
+
+	title := "partial"
+	if _, err := client.UpdateLineItem("test", "lineItem001", &samplify.UpdateLineItemCriteria{Title: &title}); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	l.FieldSchedule = samplify.NewSchedule(now.Add(time.Hour), l.DaysInField)
+	if _, err := client.AddLineItem("test", l); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestUpdateStartedSchedule(t *testing.T) {
+	start := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+	current := samplify.NewSchedule(start, 5)
+	b, _ := json.Marshal(current)
+	var posted []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			posted = append(posted, r.URL.Path)
+			w.Write([]byte(`{"data": {}}`))
+			return
+		}
+		lineItem := fmt.Sprintf(`{"extLineItemId": "li", "state": "LAUNCHED", "fieldSchedule": %s}`, b)
+		if r.URL.Path == "/projects/prj" {
+			fmt.Fprintf(w, `{"data": {"extProjectId": "prj", "lineItems": [%s]}}`, lineItem)
+			return
+		}
+		fmt.Fprintf(w, `{"data": %s}`, lineItem)
+	}))
+	defer ts.Close()
+	now := time.Now()
+	client := samplify.NewClient("", "", "", &samplify.ClientOptions{APIBaseURL: ts.URL, AuthURL: ts.URL})
+	client.Auth = samplify.TokenResponse{AccessToken: "test", Acquired: &now, ExpiresIn: 1800}
+
+	extended := samplify.NewSchedule(start, 8)
+	moved := samplify.NewSchedule(start.Add(time.Hour), 8)
+	tests := []struct {
+		name     string
+		schedule *samplify.Schedule
+		expected error
+	}{
+		{"end pushed out", extended, nil},
+		{"start kept in another time zone", extended.In(time.FixedZone("EST", -5*3600)), nil},
+		{"start moved", moved, samplify.ErrSchedulePast},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &samplify.UpdateLineItemCriteria{ExtLineItemID: "li", FieldSchedule: tt.schedule}
+			if _, err := client.UpdateLineItem("prj", "li", u); !errors.Is(err, tt.expected) {
+				t.Errorf("update line item: got error %v, want %v", err, tt.expected)
+			}
+			p := &samplify.UpdateProjectCriteria{ExtProjectID: "prj", LineItems: &[]*samplify.UpdateLineItemCriteria{u}}
+			if _, err := client.UpdateProject(p); !errors.Is(err, tt.expected) {
+				t.Errorf("update project: got error %v, want %v", err, tt.expected)
+			}
+		})
+	}
+	if len(posted) != 4 {
+		t.Errorf("got updates %v, want 4", posted)
+	}
+}
@@ -0,0 +1,47 @@
+package spec
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+// ApplyWithContext executes the plan. Nothing is changed when any of its changes is blocked.
+func ApplyWithContext(ctx context.Context, c *samplify.Client, plan *Plan) error {
+	if blocked := plan.Blocked(); len(blocked) > 0 {
+		msgs := make([]string, 0, len(blocked))
+		for _, b := range blocked {
+			msgs = append(msgs, b.String())
+		}
+		return fmt.Errorf("%w:\n%s", ErrBlockedChanges, strings.Join(msgs, "\n"))
+	}
+	if plan.Create != nil {
+		_, err := c.CreateProjectWithContext(ctx, plan.Create)
+		return err
+	}
+	if plan.Update != nil {
+		_, err := c.UpdateProjectWithContext(ctx, plan.Update)
+		if err != nil {
+			return err
+		}
+	}
+	for _, l := range plan.AddLineItems {
+		_, err := c.AddLineItemWithContext(ctx, plan.ExtProjectID, l)
+		if err != nil {
+			return fmt.Errorf("adding line item %s: %v", l.ExtLineItemID, err)
+		}
+	}
+	return nil
+}
+
+// Apply executes the plan. Nothing is changed when any of its changes is blocked.
+func Apply(c *samplify.Client, plan *Plan) error {
+	return ApplyWithContext(context.Background(), c, plan)
+}
+
+// NewPlan fetches the current state of the project and computes the plan to reach the spec.
+func NewPlan(c *samplify.Client, s *Spec) (*Plan, error) {
+	return NewPlanWithContext(context.Background(), c, s)
+}
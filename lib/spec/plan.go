@@ -0,0 +1,103 @@
+package spec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+// ErrBlockedChanges is returned by Apply when the plan contains changes the current state does not allow.
+var ErrBlockedChanges = errors.New("the plan contains changes that are not allowed in the current state")
+
+// Plan holds the requests needed to bring a project to the state described by a spec.
+type Plan struct {
+	ExtProjectID string `json:"extProjectId"`
+	// Create is set when the project does not exist yet.
+	Create *samplify.CreateProjectCriteria `json:"create,omitempty"`
+	// Update is set when the project or its existing line items have changed.
+	Update *samplify.UpdateProjectCriteria `json:"update,omitempty"`
+	// AddLineItems are line items of the spec missing from the project.
+	AddLineItems []*samplify.CreateLineItemCriteria `json:"addLineItems,omitempty"`
+	Changes      []*samplify.FieldChange            `json:"changes"`
+}
+
+// IsEmpty returns true if the project already matches the spec.
+func (p *Plan) IsEmpty() bool {
+	return p.Create == nil && p.Update == nil && len(p.AddLineItems) == 0
+}
+
+// Blocked returns the changes that are not allowed in the current state.
+func (p *Plan) Blocked() []*samplify.FieldChange {
+	var res []*samplify.FieldChange
+	for _, c := range p.Changes {
+		if len(c.Blocked) > 0 {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// WriteDiff writes one line per change.
+func (p *Plan) WriteDiff(w io.Writer) error {
+	if len(p.Changes) == 0 {
+		_, err := fmt.Fprintf(w, "project %s is up to date\n", p.ExtProjectID)
+		return err
+	}
+	for _, c := range p.Changes {
+		if _, err := fmt.Fprintln(w, c.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewPlanWithContext fetches the current state of the project and computes the plan to reach the spec.
+func NewPlanWithContext(ctx context.Context, c *samplify.Client, s *Spec) (*Plan, error) {
+	err := s.Validate()
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.GetProjectByWithContext(ctx, s.ExtProjectID)
+	if errResp, ok := err.(*samplify.ErrorResponse); ok && errResp.HTTPCode == http.StatusNotFound {
+		return Diff(nil, s), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return Diff(res.Project, s), nil
+}
+
+// Diff computes the plan to bring the current project to the spec. A nil project results in a create plan. Line
+// items of the project missing from the spec are listed as unmanaged.
+func Diff(current *samplify.Project, s *Spec) *Plan {
+	plan := &Plan{ExtProjectID: s.ExtProjectID}
+	if current == nil {
+		criteria := s.CreateProjectCriteria
+		plan.Create = &criteria
+		plan.Changes = append(plan.Changes, &samplify.FieldChange{Op: samplify.ChangeOpAdd, Path: "project " + s.ExtProjectID})
+		for _, l := range s.LineItems {
+			plan.Changes = append(plan.Changes, &samplify.FieldChange{Op: samplify.ChangeOpAdd, Path: samplify.LineItemPath(l.ExtLineItemID)})
+		}
+		return plan
+	}
+
+	diff := samplify.DiffProject(current, &s.CreateProjectCriteria)
+	plan.Update = diff.Update
+	plan.AddLineItems = diff.NewLineItems
+	plan.Changes = diff.Changes
+
+	desired := make(map[string]bool, len(s.LineItems))
+	for _, l := range s.LineItems {
+		desired[l.ExtLineItemID] = true
+	}
+	for _, l := range current.LineItems {
+		if !desired[l.ExtLineItemID] {
+			plan.Changes = append(plan.Changes, &samplify.FieldChange{Op: samplify.ChangeOpUnmanaged, Path: samplify.LineItemPath(l.ExtLineItemID)})
+		}
+	}
+	return plan
+}
@@ -0,0 +1,88 @@
+// Package spec reads declarative project definitions and reconciles them with the projects in the Samplify API.
+//
+// A spec uses the same fields as samplify.CreateProjectCriteria and may be written in YAML or JSON:
+//
+//	version: 1
+//	extProjectId: prj01
+//	title: Samplify Test Project 01
+//	notificationEmails: [api-test@researchnow.com]
+//	category:
+//	  surveyTopic: [AUTOMOTIVE]
+//	lineItems:
+//	  - extLineItemId: lineItem001
+//	    title: US College
+//	    countryISOCode: US
+//	    languageISOCode: en
+//	    indicativeIncidence: 20
+//	    lengthOfInterview: 10
+//	    daysInField: 20
+//	    targets:
+//	      - count: 200
+//	        type: COMPLETE
+package spec
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+	"sigs.k8s.io/yaml"
+)
+
+// Version is the spec format version understood by this package.
+const Version = 1
+
+// Spec errors
+var (
+	ErrUnsupportedVersion  = errors.New("unsupported spec version")
+	ErrMissingExtProjectID = errors.New("the spec has no extProjectId")
+	ErrDuplicateLineItem   = errors.New("the spec lists a line item more than once")
+)
+
+// Spec is the desired state of a project and its line items.
+type Spec struct {
+	Version int `json:"version,omitempty"`
+	samplify.CreateProjectCriteria
+}
+
+// Parse reads a YAML or JSON spec. Unknown fields are rejected so that typos do not go unnoticed.
+func Parse(data []byte) (*Spec, error) {
+	s := &Spec{}
+	err := yaml.UnmarshalStrict(data, s)
+	if err != nil {
+		return nil, err
+	}
+	return s, s.Validate()
+}
+
+// Load reads a YAML or JSON spec from a file.
+func Load(path string) (*Spec, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return s, nil
+}
+
+// Validate checks the spec format. The criteria themselves are validated by the client when the spec is applied.
+func (s *Spec) Validate() error {
+	if s.Version != 0 && s.Version != Version {
+		return ErrUnsupportedVersion
+	}
+	if len(s.ExtProjectID) == 0 {
+		return ErrMissingExtProjectID
+	}
+	seen := make(map[string]bool, len(s.LineItems))
+	for _, l := range s.LineItems {
+		if seen[l.ExtLineItemID] {
+			return fmt.Errorf("%w: %s", ErrDuplicateLineItem, l.ExtLineItemID)
+		}
+		seen[l.ExtLineItemID] = true
+	}
+	return nil
+}
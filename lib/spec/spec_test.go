@@ -0,0 +1,159 @@
+package spec_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+	"github.com/morningconsult/go-samplifyapi-client/lib/spec"
+)
+
+const testSpec = `
+version: 1
+extProjectId: prj01
+title: New title
+notificationEmails: [b@example.com, a@example.com]
+category:
+  surveyTopic: [AUTOMOTIVE]
+lineItems:
+  - extLineItemId: li1
+    title: US College
+    countryISOCode: US
+    languageISOCode: en
+    indicativeIncidence: 20
+    lengthOfInterview: 15
+    daysInField: 20
+    quotaPlan:
+      quotaGroups:
+        - name: gender
+          quotaCells:
+            - quotaNodes: [{attributeId: "11", options: ["1"]}]
+              perc: 50
+            - quotaNodes: [{attributeId: "11", options: ["2"]}]
+              perc: 50
+  - extLineItemId: li2
+    title: UK College
+    countryISOCode: GB
+    languageISOCode: en
+    indicativeIncidence: 20
+    lengthOfInterview: 10
+    daysInField: 20
+`
+
+const currentProject = `{
+	"extProjectId": "prj01",
+	"title": "Old title",
+	"state": "LAUNCHED",
+	"notificationEmails": ["a@example.com", "b@example.com"],
+	"category": {"surveyTopic": ["AUTOMOTIVE"], "studyType": null, "StudyRequirements": null},
+	"lineItems": [{
+		"extLineItemId": "li1",
+		"state": "%s",
+		"title": "US College",
+		"countryISOCode": "US",
+		"languageISOCode": "en",
+		"indicativeIncidence": 20,
+		"lengthOfInterview": 10,
+		"daysInField": 20,
+		"quotaPlan": {"quotaGroups": [{"quotaGroupId": "g1", "name": "gender", "quotaCells": [
+			{"quotaCellId": "1", "quotaNodes": [{"attributeId": "11", "options": ["1"]}], "perc": 50, "status": "LAUNCHED"},
+			{"quotaCellId": "2", "quotaNodes": [{"attributeId": "11", "options": ["2"]}], "perc": 50, "status": "LAUNCHED"}
+		]}]}
+	}, {"extLineItemId": "li3", "state": "LAUNCHED"}]
+}`
+
+func TestParse(t *testing.T) {
+	s, err := spec.Parse([]byte(testSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.ExtProjectID != "prj01" || len(s.LineItems) != 2 || s.LineItems[0].QuotaPlan == nil {
+		t.Fatalf("unexpected spec %+v", s)
+	}
+
+	tables := []struct {
+		name     string
+		input    string
+		expected error
+	}{
+		{"Case 1: unknown field", "extProjectId: a\ntitel: b", nil},
+		{"Case 2: missing project id", "title: b", spec.ErrMissingExtProjectID},
+		{"Case 3: unsupported version", "version: 2\nextProjectId: a", spec.ErrUnsupportedVersion},
+		{"Case 4: duplicate line item", "extProjectId: a\nlineItems: [{extLineItemId: l}, {extLineItemId: l}]", spec.ErrDuplicateLineItem},
+	}
+	for _, table := range tables {
+		_, err := spec.Parse([]byte(table.input))
+		if err == nil || table.expected != nil && !errors.Is(err, table.expected) {
+			t.Errorf("%s: got `%v`, want `%v`", table.name, err, table.expected)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	s, err := spec.Parse([]byte(testSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tables := []struct {
+		name    string
+		state   samplify.State
+		changes []string
+		blocked int
+	}{
+		{
+			"Case 1: provisioned line item can be updated",
+			samplify.StateProvisioned,
+			[]string{
+				`~ title: "Old title" -> "New title"`,
+				`~ lineItems[li1].lengthOfInterview: 10 -> 15`,
+				`+ lineItems[li2]`,
+				`? lineItems[li3]`,
+			},
+			0,
+		},
+		{
+			"Case 2: launched line item blocks the change",
+			samplify.StateLaunched,
+			[]string{
+				`~ title: "Old title" -> "New title"`,
+				`~ lineItems[li1].lengthOfInterview: 10 -> 15 (blocked: line item is LAUNCHED)`,
+				`+ lineItems[li2]`,
+				`? lineItems[li3]`,
+			},
+			1,
+		},
+	}
+
+	for _, table := range tables {
+		p := &samplify.Project{}
+		if err := json.Unmarshal([]byte(strings.Replace(currentProject, "%s", string(table.state), 1)), p); err != nil {
+			t.Fatal(err)
+		}
+		plan := spec.Diff(p, s)
+		buf := &bytes.Buffer{}
+		plan.WriteDiff(buf)
+		if got := strings.TrimSpace(buf.String()); got != strings.Join(table.changes, "\n") {
+			t.Errorf("%s: got diff\n%s\nwant\n%s", table.name, got, strings.Join(table.changes, "\n"))
+		}
+		if len(plan.Blocked()) != table.blocked {
+			t.Errorf("%s: got %d blocked changes, want %d", table.name, len(plan.Blocked()), table.blocked)
+		}
+		if plan.Update == nil || plan.Update.NotificationEmails != nil || plan.Update.Category != nil {
+			t.Errorf("%s: expected a minimal update, got %+v", table.name, plan.Update)
+		}
+		if plan.Update.LineItems == nil || len(*plan.Update.LineItems) != 1 || (*plan.Update.LineItems)[0].QuotaPlan != nil {
+			t.Errorf("%s: expected only li1 to be updated without its quota plan", table.name)
+		}
+		if len(plan.AddLineItems) != 1 || plan.AddLineItems[0].ExtLineItemID != "li2" {
+			t.Errorf("%s: expected li2 to be added", table.name)
+		}
+	}
+
+	if plan := spec.Diff(nil, s); plan.Create == nil || plan.Update != nil {
+		t.Error("expected a create plan for a missing project")
+	}
+}
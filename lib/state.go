@@ -0,0 +1,185 @@
+package samplify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ActionBuy is the action of buying a project with BuyProject. It cannot be passed to UpdateLineItemState.
+const ActionBuy Action = "buy"
+
+// Errors returned by the state checks
+var (
+	ErrInvalidTransition = errors.New("invalid state transition")
+	ErrQuotaCellNotFound = errors.New("quota cell not found in the line item")
+)
+
+// TransitionError is returned before calling the API when an action is not allowed in the current state of a line
+// item or quota cell. It matches ErrInvalidTransition with errors.Is.
+type TransitionError struct {
+	ExtLineItemID string
+	QuotaCellID   string
+	State         State
+	Action        Action
+}
+
+func (e *TransitionError) Error() string {
+	if len(e.QuotaCellID) > 0 {
+		return fmt.Sprintf("cannot %s quota cell %s of line item %s: %s", e.Action, e.QuotaCellID, e.ExtLineItemID, e.State)
+	}
+	return fmt.Sprintf("cannot %s line item %s: %s", e.Action, e.ExtLineItemID, e.State)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return ErrInvalidTransition
+}
+
+// Transition is a change of state. Transitions without an action are made by the platform, e.g. when a line item is
+// approved or reaches its target.
+type Transition struct {
+	From   State
+	Action Action
+	To     State
+}
+
+// transitions is the state machine shared by projects and line items.
+var transitions = []Transition{
+	{StateProvisioned, ActionBuy, StateAwaitingApproval},
+	{StateProvisioned, ActionClosed, StateClosed},
+	{StateProvisioned, "", StateCancelled},
+
+	{StateAwaitingApproval, ActionPaused, StateAwaitingApprovalPaused},
+	{StateAwaitingApproval, ActionClosed, StateClosed},
+	{StateAwaitingApproval, "", StateQAApproved},
+	{StateAwaitingApproval, "", StateAwaitingClientApproval},
+	{StateAwaitingApproval, "", StateLaunched},
+	{StateAwaitingApproval, "", StateRejected},
+
+	{StateAwaitingApprovalPaused, ActionLaunched, StateAwaitingApproval},
+	{StateAwaitingApprovalPaused, ActionClosed, StateClosed},
+	{StateAwaitingApprovalPaused, "", StatePaused},
+	{StateAwaitingApprovalPaused, "", StateRejectedPaused},
+
+	{StateAwaitingClientApproval, ActionClosed, StateClosed},
+	{StateAwaitingClientApproval, "", StateLaunched},
+	{StateAwaitingClientApproval, "", StateRejected},
+
+	{StateQAApproved, ActionClosed, StateClosed},
+	{StateQAApproved, "", StateLaunched},
+
+	{StateRejected, ActionBuy, StateAwaitingApproval},
+	{StateRejected, ActionClosed, StateClosed},
+
+	{StateRejectedPaused, ActionBuy, StateAwaitingApprovalPaused},
+	{StateRejectedPaused, ActionClosed, StateClosed},
+
+	{StateLaunched, ActionPaused, StatePaused},
+	{StateLaunched, ActionClosed, StateClosed},
+	{StateLaunched, "", StateCompleted},
+
+	{StatePaused, ActionLaunched, StateLaunched},
+	{StatePaused, ActionClosed, StateClosed},
+
+	{StateCompleted, ActionClosed, StateClosed},
+
+	{StateClosed, "", StateInvoiced},
+}
+
+// Transitions returns the transitions out of a state.
+func Transitions(from State) []Transition {
+	var res []Transition
+	for _, t := range transitions {
+		if t.From == from {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+// CanApply tells whether an action is allowed in a state.
+func CanApply(state State, action Action) bool {
+	_, err := NextState(state, action)
+	return err == nil
+}
+
+// NextState returns the state reached by applying an action, or ErrInvalidTransition if it is not allowed.
+func NextState(state State, action Action) (State, error) {
+	for _, t := range transitions {
+		if t.From == state && len(action) > 0 && t.Action == action {
+			return t.To, nil
+		}
+	}
+	return state, ErrInvalidTransition
+}
+
+// NextStates returns the states that can follow a state, through an action or the platform.
+func NextStates(state State) []State {
+	var res []State
+	seen := make(map[State]bool)
+	for _, t := range Transitions(state) {
+		if !seen[t.To] {
+			seen[t.To] = true
+			res = append(res, t.To)
+		}
+	}
+	return res
+}
+
+// CanApplyToQuotaCell tells whether a quota cell with the given status can be launched or paused.
+func CanApplyToQuotaCell(status QCellStatusType, action Action) bool {
+	switch action {
+	case ActionLaunched:
+		return status != QCellStatusTypeLaunch
+	case ActionPaused:
+		return status != QCellStatusTypePause
+	}
+	return false
+}
+
+func (c *Client) checkLineItemTransition(ctx context.Context, extProjectID, extLineItemID string, action Action) error {
+	res, err := c.GetLineItemByWithContext(ctx, extProjectID, extLineItemID)
+	if err != nil {
+		return err
+	}
+	if res.Item == nil {
+		return ErrLineItemNotFound
+	}
+	if !CanApply(res.Item.State, action) {
+		return &TransitionError{ExtLineItemID: extLineItemID, State: res.Item.State, Action: action}
+	}
+	return nil
+}
+
+func (c *Client) checkQuotaCellTransition(ctx context.Context, extProjectID, extLineItemID, quotaCellID string, action Action) error {
+	res, err := c.GetLineItemByWithContext(ctx, extProjectID, extLineItemID)
+	if err != nil {
+		return err
+	}
+	if res.Item == nil {
+		return ErrLineItemNotFound
+	}
+	invalid := &TransitionError{ExtLineItemID: extLineItemID, QuotaCellID: quotaCellID, State: res.Item.State, Action: action}
+	if !res.Item.IsRebalanceable() {
+		return invalid
+	}
+	if res.Item.QuotaPlan != nil {
+		for _, g := range res.Item.QuotaPlan.QuotaGroups {
+			for _, cell := range g.QuotaCells {
+				if cell.QuotaCellID == nil || *cell.QuotaCellID != quotaCellID {
+					continue
+				}
+				var status QCellStatusType
+				if cell.Status != nil {
+					status = *cell.Status
+				}
+				if !CanApplyToQuotaCell(status, action) {
+					invalid.State = State(status)
+					return invalid
+				}
+				return nil
+			}
+		}
+	}
+	return ErrQuotaCellNotFound
+}
@@ -0,0 +1,125 @@
+package samplify_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+func TestCanApply(t *testing.T) {
+	tables := []struct {
+		state    samplify.State
+		action   samplify.Action
+		expected bool
+	}{
+		{samplify.StateProvisioned, samplify.ActionBuy, true},
+		{samplify.StateProvisioned, samplify.ActionLaunched, false},
+		{samplify.StateLaunched, samplify.ActionPaused, true},
+		{samplify.StateLaunched, samplify.ActionLaunched, false},
+		{samplify.StatePaused, samplify.ActionLaunched, true},
+		{samplify.StateAwaitingApprovalPaused, samplify.ActionLaunched, true},
+		{samplify.StateRejectedPaused, samplify.ActionBuy, true},
+		{samplify.StateClosed, samplify.ActionClosed, false},
+		{samplify.StateInvoiced, samplify.ActionPaused, false},
+		{samplify.StateLaunched, "", false},
+	}
+
+	for _, table := range tables {
+		if got := samplify.CanApply(table.state, table.action); got != table.expected {
+			t.Errorf("CanApply(%s, %s): got %t, want %t", table.state, table.action, got, table.expected)
+		}
+	}
+}
+
+func TestNextStates(t *testing.T) {
+	tables := []struct {
+		state    samplify.State
+		expected []samplify.State
+	}{
+		{samplify.StateLaunched, []samplify.State{samplify.StatePaused, samplify.StateClosed, samplify.StateCompleted}},
+		{samplify.StateClosed, []samplify.State{samplify.StateInvoiced}},
+		{samplify.StateInvoiced, nil},
+		{samplify.StateCancelled, nil},
+	}
+
+	for _, table := range tables {
+		if got := samplify.NextStates(table.state); fmt.Sprint(got) != fmt.Sprint(table.expected) {
+			t.Errorf("NextStates(%s): got %v, want %v", table.state, got, table.expected)
+		}
+	}
+
+	// Every state but the final ones must have a way out.
+	all := []samplify.State{
+		samplify.StateProvisioned, samplify.StateLaunched, samplify.StatePaused, samplify.StateCompleted,
+		samplify.StateAwaitingApproval, samplify.StateQAApproved, samplify.StateRejected,
+		samplify.StateAwaitingApprovalPaused, samplify.StateAwaitingClientApproval, samplify.StateRejectedPaused,
+	}
+	for _, s := range all {
+		if len(samplify.NextStates(s)) == 0 {
+			t.Errorf("NextStates(%s): got no state", s)
+		}
+	}
+}
+
+func TestTransitionCheck(t *testing.T) {
+	tables := []struct {
+		name   string
+		skip   bool
+		call   func(c *samplify.Client) error
+		posted bool
+	}{
+		{"Case 1: pause a launched line item", false, func(c *samplify.Client) error {
+			_, err := c.PauseLineItem("prj", "li")
+			return err
+		}, true},
+		{"Case 2: launch a launched line item", false, func(c *samplify.Client) error {
+			_, err := c.LaunchLineItem("prj", "li")
+			return err
+		}, false},
+		{"Case 3: launch a launched line item without the check", true, func(c *samplify.Client) error {
+			_, err := c.LaunchLineItem("prj", "li")
+			return err
+		}, true},
+		{"Case 4: pause a launched quota cell", false, func(c *samplify.Client) error {
+			_, err := c.SetQuotaCellStatus("prj", "li", "1", samplify.ActionPaused)
+			return err
+		}, true},
+		{"Case 5: pause a paused quota cell", false, func(c *samplify.Client) error {
+			_, err := c.SetQuotaCellStatus("prj", "li", "2", samplify.ActionPaused)
+			return err
+		}, false},
+	}
+
+	for _, table := range tables {
+		posted := false
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "POST" {
+				posted = true
+				w.Write([]byte(`{"data": {}}`))
+				return
+			}
+			w.Write([]byte(`{"data": {"extLineItemId": "li", "state": "LAUNCHED", "quotaPlan": {"quotaGroups": [{"quotaCells": [
+				{"quotaCellId": "1", "status": "LAUNCHED"},
+				{"quotaCellId": "2", "status": "PAUSED"}
+			]}]}}}`))
+		}))
+		client := samplify.NewClient("", "", "", &samplify.ClientOptions{APIBaseURL: ts.URL, AuthURL: ts.URL, SkipTransitionCheck: table.skip})
+		client.Auth = getAuth()
+		err := table.call(client)
+		ts.Close()
+
+		if posted != table.posted {
+			t.Errorf("%s: got posted %t, want %t", table.name, posted, table.posted)
+		}
+		if table.posted && err != nil {
+			t.Errorf("%s: %v", table.name, err)
+		}
+		if !table.posted && !errors.Is(err, samplify.ErrInvalidTransition) {
+			t.Errorf("%s: got error %v, want ErrInvalidTransition", table.name, err)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/asaskevich/govalidator"
@@ -28,6 +29,11 @@ var (
 	ErrInconsistentAllocationType = errors.New("allocation type with in the quota group should be consistent")
 	ErrMissingQuotaCells          = errors.New("at least one quota cell should be provided")
 	ErrInvalidScheduleEmpty       = errors.New("both days in field and scheduled times cannot be empty")
+	ErrScheduleIncomplete         = errors.New("the field schedule must have a start and an end time")
+	ErrScheduleOrder              = errors.New("the field schedule must start before it ends")
+	ErrSchedulePast               = errors.New("the field schedule must start in the future")
+	ErrScheduleTime               = errors.New("the field schedule time must be RFC 3339 with a time zone")
+	ErrScheduleDaysInField        = errors.New("the days in field do not match the field schedule")
 
 	// URL validation errors
 	ErrURLBlank      = errors.New("the URL cannot be blank")
@@ -119,10 +125,34 @@ func ValidateLanguageISOCode(val string) error {
 	return ErrInvalidFieldValue
 }
 
+// ValidateSchedule checks that the days in field or the field schedule are set, that the schedule is valid and starts
+// in the future, and that both agree when both are set.
 func ValidateSchedule(daysInField *int64, fieldSchedule *Schedule) error {
-	if (daysInField == nil || *daysInField == 0) && fieldSchedule == nil {
+	noDays := daysInField == nil || *daysInField == 0
+	if noDays && fieldSchedule == nil {
 		return ErrInvalidScheduleEmpty
 	}
+	return validateScheduleUpdate(daysInField, fieldSchedule, time.Time{})
+}
+
+// validateScheduleUpdate checks the field schedule of a partial update, which may leave both the days in field and
+// the schedule unchanged. A schedule that keeps currentStart, the start the line item already has, may start in the
+// past: only new starts must be in the future.
+func validateScheduleUpdate(daysInField *int64, fieldSchedule *Schedule, currentStart time.Time) error {
+	if fieldSchedule == nil {
+		return nil
+	}
+	if err := fieldSchedule.validateTimes(); err != nil {
+		return err
+	}
+	if currentStart.IsZero() || !currentStart.Equal(fieldSchedule.StartTime) {
+		if err := fieldSchedule.validateStart(); err != nil {
+			return err
+		}
+	}
+	if daysInField != nil && *daysInField != 0 && *daysInField != fieldSchedule.Days() {
+		return fmt.Errorf("%w: %d days in field, %d days scheduled", ErrScheduleDaysInField, *daysInField, fieldSchedule.Days())
+	}
 	return nil
 }
 
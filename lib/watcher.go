@@ -0,0 +1,300 @@
+package samplify
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Default intervals of a Watcher
+const (
+	DefaultWatchInterval   = time.Minute
+	DefaultWatchMaxBackoff = 15 * time.Minute
+)
+
+// WatchedState is the last known state of a project or line item.
+type WatchedState struct {
+	State              State      `json:"state"`
+	StateReason        string     `json:"stateReason,omitempty"`
+	StateLastUpdatedAt *time.Time `json:"stateLastUpdatedAt,omitempty"`
+}
+
+func (s *WatchedState) equal(o *WatchedState) bool {
+	if s.State != o.State || s.StateReason != o.StateReason {
+		return false
+	}
+	if s.StateLastUpdatedAt == nil || o.StateLastUpdatedAt == nil {
+		return s.StateLastUpdatedAt == o.StateLastUpdatedAt
+	}
+	return s.StateLastUpdatedAt.Equal(*o.StateLastUpdatedAt)
+}
+
+// WatchedProject is the last known state of a project and its line items.
+type WatchedProject struct {
+	WatchedState
+	LineItems map[string]*WatchedState `json:"lineItems"`
+}
+
+// WatcherCheckpoint holds the last known states of the watched projects, so that a Watcher can be resumed without
+// missing or repeating changes.
+type WatcherCheckpoint struct {
+	Projects map[string]*WatchedProject `json:"projects"`
+}
+
+// StateChange is a change of the state, state reason or state update time of a project or line item. ExtLineItemID is
+// empty for changes of the project itself. Old is nil for line items added to a watched project.
+type StateChange struct {
+	ExtProjectID  string
+	ExtLineItemID string
+	Old           *WatchedState
+	New           *WatchedState
+	DetectedAt    time.Time
+}
+
+// Watcher polls projects and notifies the changes of state of the projects and their line items. The first poll of
+// a project that is not in the checkpoint only records its state.
+type Watcher struct {
+	Client *Client
+	// Interval is the delay between two polls of a project.
+	Interval time.Duration
+	// MaxBackoff caps the delay between two polls of a project that keeps failing. The delay doubles on every error.
+	MaxBackoff time.Duration
+	// Changes receives every change. Sends block until received or the context is done.
+	Changes chan<- *StateChange
+	// OnChange is called for every change.
+	OnChange func(*StateChange)
+	// OnError is called when a project cannot be polled.
+	OnError func(extProjectID string, err error)
+	// Checkpoint holds the last known states. It is updated after every poll.
+	Checkpoint *WatcherCheckpoint
+	// OnCheckpoint is called after every successful poll of a project, e.g. to persist the checkpoint.
+	OnCheckpoint func(*WatcherCheckpoint) error
+
+	mu       sync.Mutex
+	schedule map[string]*watchSchedule
+}
+
+type watchSchedule struct {
+	next    time.Time
+	backoff time.Duration
+}
+
+// NewWatcher returns a watcher of the given projects, with the default intervals.
+func NewWatcher(c *Client, extProjectIDs ...string) *Watcher {
+	w := &Watcher{Client: c}
+	for _, id := range extProjectIDs {
+		w.Add(id)
+	}
+	return w
+}
+
+// Add starts watching a project. It is polled on the next run.
+func (w *Watcher) Add(extProjectID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.schedule == nil {
+		w.schedule = make(map[string]*watchSchedule)
+	}
+	if _, ok := w.schedule[extProjectID]; !ok {
+		w.schedule[extProjectID] = &watchSchedule{}
+	}
+}
+
+// Remove stops watching a project.
+func (w *Watcher) Remove(extProjectID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.schedule, extProjectID)
+}
+
+// RunWithContext polls the watched projects until the context is done, and returns the context error.
+func (w *Watcher) RunWithContext(ctx context.Context) error {
+	for {
+		next, err := w.PollWithContext(ctx)
+		if err != nil {
+			return err
+		}
+		delay := w.interval()
+		if !next.IsZero() {
+			delay = time.Until(next)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Run polls the watched projects forever.
+func (w *Watcher) Run() error {
+	return w.RunWithContext(context.Background())
+}
+
+// PollWithContext polls the projects that are due, and returns when the next one is. Errors polling a project are
+// reported to OnError and delay its next poll; only context and checkpoint errors are returned.
+func (w *Watcher) PollWithContext(ctx context.Context) (time.Time, error) {
+	now := time.Now()
+	for _, id := range w.due(now) {
+		err := w.poll(ctx, id)
+		if ctx.Err() != nil {
+			return time.Time{}, ctx.Err()
+		}
+		w.reschedule(id, err)
+		if err != nil {
+			if w.OnError != nil {
+				w.OnError(id, err)
+			}
+			continue
+		}
+		if w.OnCheckpoint != nil {
+			if err := w.OnCheckpoint(w.Checkpoint); err != nil {
+				return time.Time{}, err
+			}
+		}
+	}
+	return w.nextPoll(), nil
+}
+
+// Poll polls the projects that are due, and returns when the next one is.
+func (w *Watcher) Poll() (time.Time, error) {
+	return w.PollWithContext(context.Background())
+}
+
+func (w *Watcher) interval() time.Duration {
+	if w.Interval <= 0 {
+		return DefaultWatchInterval
+	}
+	return w.Interval
+}
+
+func (w *Watcher) due(now time.Time) []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var ids []string
+	for id, s := range w.schedule {
+		if !s.next.After(now) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (w *Watcher) reschedule(extProjectID string, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	s, ok := w.schedule[extProjectID]
+	if !ok {
+		return
+	}
+	if err == nil {
+		s.backoff = 0
+		s.next = time.Now().Add(w.interval())
+		return
+	}
+	maxBackoff := w.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultWatchMaxBackoff
+	}
+	if maxBackoff < w.interval() {
+		maxBackoff = w.interval()
+	}
+	s.backoff *= 2
+	if s.backoff == 0 {
+		s.backoff = w.interval()
+	}
+	if s.backoff > maxBackoff {
+		s.backoff = maxBackoff
+	}
+	s.next = time.Now().Add(s.backoff)
+}
+
+func (w *Watcher) nextPoll() time.Time {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var next time.Time
+	for _, s := range w.schedule {
+		if next.IsZero() || s.next.Before(next) {
+			next = s.next
+		}
+	}
+	return next
+}
+
+func (w *Watcher) poll(ctx context.Context, extProjectID string) error {
+	res, err := w.Client.GetProjectByWithContext(ctx, extProjectID)
+	if err != nil {
+		return err
+	}
+	if res.Project == nil {
+		return ErrRequiredFieldEmpty
+	}
+	p := res.Project
+	current := &WatchedProject{
+		WatchedState: watchedState(p.State, "", p.StateLastUpdatedAt),
+		LineItems:    make(map[string]*WatchedState, len(p.LineItems)),
+	}
+	for _, l := range p.LineItems {
+		s := watchedState(l.State, l.StateReason, l.StateLastUpdatedAt)
+		current.LineItems[l.ExtLineItemID] = &s
+	}
+
+	if w.Checkpoint == nil {
+		w.Checkpoint = &WatcherCheckpoint{}
+	}
+	if w.Checkpoint.Projects == nil {
+		w.Checkpoint.Projects = make(map[string]*WatchedProject)
+	}
+	previous, known := w.Checkpoint.Projects[extProjectID]
+	if !known {
+		w.Checkpoint.Projects[extProjectID] = current
+		return nil
+	}
+
+	now := time.Now()
+	var changes []*StateChange
+	if !current.equal(&previous.WatchedState) {
+		old := previous.WatchedState
+		changes = append(changes, &StateChange{ExtProjectID: extProjectID, Old: &old, New: &current.WatchedState, DetectedAt: now})
+	}
+	for _, l := range p.LineItems {
+		s := current.LineItems[l.ExtLineItemID]
+		old := previous.LineItems[l.ExtLineItemID]
+		if old == nil || !s.equal(old) {
+			changes = append(changes, &StateChange{
+				ExtProjectID:  extProjectID,
+				ExtLineItemID: l.ExtLineItemID,
+				Old:           old,
+				New:           s,
+				DetectedAt:    now,
+			})
+		}
+	}
+	for _, c := range changes {
+		if w.OnChange != nil {
+			w.OnChange(c)
+		}
+		if w.Changes != nil {
+			select {
+			case w.Changes <- c:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	w.Checkpoint.Projects[extProjectID] = current
+	return nil
+}
+
+func watchedState(state State, reason string, updatedAt *CustomTime) WatchedState {
+	s := WatchedState{State: state, StateReason: reason}
+	if updatedAt != nil && updatedAt.IsSet() {
+		t := updatedAt.Time
+		s.StateLastUpdatedAt = &t
+	}
+	return s
+}
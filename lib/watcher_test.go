@@ -0,0 +1,107 @@
+package samplify_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+func TestWatcher(t *testing.T) {
+	var mu sync.Mutex
+	project := `{"data": {"extProjectId": "prj", "state": "PROVISIONED", "lineItems": [
+		{"extLineItemId": "li1", "state": "PROVISIONED"}
+	]}}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path != "/projects/prj" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(project))
+	}))
+	defer ts.Close()
+	setProject := func(p string) {
+		mu.Lock()
+		defer mu.Unlock()
+		project = p
+	}
+	client := samplify.NewClient("", "", "", &samplify.ClientOptions{APIBaseURL: ts.URL, AuthURL: ts.URL})
+	client.Auth = getAuth()
+
+	var changes []string
+	record := func(c *samplify.StateChange) {
+		old := ""
+		if c.Old != nil {
+			old = string(c.Old.State)
+		}
+		changes = append(changes, fmt.Sprintf("%s/%s:%s->%s", c.ExtProjectID, c.ExtLineItemID, old, c.New.State))
+	}
+	var saved []byte
+	w := samplify.NewWatcher(client, "prj")
+	w.Interval = time.Nanosecond
+	w.OnChange = record
+	w.OnCheckpoint = func(cp *samplify.WatcherCheckpoint) error {
+		var err error
+		saved, err = json.Marshal(cp)
+		return err
+	}
+
+	if _, err := w.Poll(); err != nil || len(changes) != 0 {
+		t.Fatalf("expected the first poll to only record the states, got %v, %v", changes, err)
+	}
+	setProject(`{"data": {"extProjectId": "prj", "state": "PROVISIONED", "lineItems": [
+		{"extLineItemId": "li1", "state": "LAUNCHED", "stateLastUpdatedAt": "2019/01/02 10:00:00"},
+		{"extLineItemId": "li2", "state": "PROVISIONED"}
+	]}}`)
+	w.Poll()
+	expected := []string{"prj/li1:PROVISIONED->LAUNCHED", "prj/li2:->PROVISIONED"}
+	if fmt.Sprint(changes) != fmt.Sprint(expected) {
+		t.Errorf("got changes %v, want %v", changes, expected)
+	}
+
+	// A new watcher resumed from the saved checkpoint only notifies new changes.
+	changes = nil
+	checkpoint := &samplify.WatcherCheckpoint{}
+	if err := json.Unmarshal(saved, checkpoint); err != nil {
+		t.Fatal(err)
+	}
+	updates := make(chan *samplify.StateChange, 10)
+	w = samplify.NewWatcher(client, "prj")
+	w.Interval = time.Nanosecond
+	w.Checkpoint = checkpoint
+	w.Changes = updates
+	w.Poll()
+	setProject(`{"data": {"extProjectId": "prj", "state": "CLOSED", "lineItems": [
+		{"extLineItemId": "li1", "state": "LAUNCHED", "stateLastUpdatedAt": "2019/01/02 10:00:00"},
+		{"extLineItemId": "li2", "state": "REJECTED", "stateReason": "Survey link broken"}
+	]}}`)
+	w.Poll()
+	close(updates)
+	for c := range updates {
+		record(c)
+	}
+	expected = []string{"prj/:PROVISIONED->CLOSED", "prj/li2:PROVISIONED->REJECTED"}
+	if fmt.Sprint(changes) != fmt.Sprint(expected) {
+		t.Errorf("got changes %v, want %v", changes, expected)
+	}
+
+	// A project that fails is polled less and less often.
+	var errs []string
+	w = samplify.NewWatcher(client, "broken")
+	w.Interval = time.Hour
+	w.OnError = func(id string, err error) { errs = append(errs, id) }
+	next, err := w.Poll()
+	if err != nil || len(errs) != 1 || time.Until(next) < 59*time.Minute {
+		t.Errorf("expected the failing project to be retried in an hour, got %v, %v, %v", errs, next, err)
+	}
+	if w.Poll(); len(errs) != 1 {
+		t.Errorf("expected the failing project not to be polled before its backoff, got %v", errs)
+	}
+}
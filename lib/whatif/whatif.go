@@ -0,0 +1,331 @@
+// Package whatif compares the feasibility and cost of variations of a line item, using scratch projects.
+package whatif
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+)
+
+// DefaultLineItemsPerProject is the number of scenarios priced in one scratch project.
+const DefaultLineItemsPerProject = 10
+
+// Errors returned by the Explorer
+var (
+	ErrNoScenarios     = errors.New("the grid has no scenario")
+	ErrMissingTemplate = errors.New("a line item template is required")
+	ErrQuotaPlanLocale = errors.New("the quota plan of the template only applies to its own locale")
+)
+
+// Locale is a country and language.
+type Locale struct {
+	CountryISOCode  string
+	LanguageISOCode string
+}
+
+// Grid lists the values to try for each parameter. Every combination is a scenario, and an empty list keeps the value
+// of the line item template.
+type Grid struct {
+	Locales    []Locale
+	Incidences []float64
+	LOIs       []int64
+	Counts     []uint32
+}
+
+// Scenario is one combination of parameters. Count is 0 when the template targets are kept.
+type Scenario struct {
+	Locale
+	Incidence float64
+	LOI       int64
+	Count     uint32
+}
+
+func (s Scenario) String() string {
+	res := fmt.Sprintf("%s-%s IR %g%% LOI %dmin", s.CountryISOCode, s.LanguageISOCode, s.Incidence, s.LOI)
+	if s.Count > 0 {
+		res = fmt.Sprintf("%s N=%d", res, s.Count)
+	}
+	return res
+}
+
+// Scenarios returns every combination of the grid, using the template for the empty lists. Locales vary the slowest.
+func (g *Grid) Scenarios(template *samplify.CreateLineItemCriteria) []Scenario {
+	locales := g.Locales
+	if len(locales) == 0 {
+		locales = []Locale{{template.CountryISOCode, template.LanguageISOCode}}
+	}
+	incidences := g.Incidences
+	if len(incidences) == 0 {
+		incidences = []float64{template.IndicativeIncidence}
+	}
+	lois := g.LOIs
+	if len(lois) == 0 {
+		lois = []int64{template.LengthOfInterview}
+	}
+	counts := g.Counts
+	if len(counts) == 0 {
+		counts = []uint32{0}
+	}
+	var res []Scenario
+	for _, l := range locales {
+		for _, i := range incidences {
+			for _, loi := range lois {
+				for _, c := range counts {
+					res = append(res, Scenario{Locale: l, Incidence: i, LOI: loi, Count: c})
+				}
+			}
+		}
+	}
+	return res
+}
+
+// Result is the feasibility and quote of a scenario. Err is set when the scenario could not be priced.
+type Result struct {
+	Scenario
+	ExtProjectID  string
+	ExtLineItemID string
+	Status        samplify.FeasibilityStatus
+	Feasibility   *samplify.Feasibility
+	Quote         samplify.Quote
+	Err           error
+}
+
+// Feasible tells whether the scenario is READY and feasible.
+func (r *Result) Feasible() bool {
+	return r.Err == nil && r.Feasibility != nil && r.Status == samplify.FeasibilityStatusReady && r.Feasibility.Feasible
+}
+
+// Matrix holds the results of every scenario, in the order of the grid.
+type Matrix struct {
+	Results []*Result
+}
+
+// Cheapest returns the feasible scenario with the lowest cost per interview, or nil if none is feasible.
+func (m *Matrix) Cheapest() *Result {
+	var res *Result
+	for _, r := range m.Results {
+		if r.Feasible() && (res == nil || r.Feasibility.CostPerInterview < res.Feasibility.CostPerInterview) {
+			res = r
+		}
+	}
+	return res
+}
+
+// WriteCSV writes one row per scenario, with its parameters, feasibility and quote. The costs per unit of the detailed
+// quote are written as TYPE:title=cost, separated by semicolons.
+func (m *Matrix) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"country", "language", "incidence", "loi", "count", "status", "feasible", "totalCount",
+		"costPerInterview", "currency", "quoteCostPerUnit", "quoteEstimatedCost", "detailedQuote", "error"})
+	if err != nil {
+		return err
+	}
+	for _, r := range m.Results {
+		var totalCount, cpi, currency, errMsg string
+		if r.Feasibility != nil {
+			totalCount = strconv.FormatInt(r.Feasibility.TotalCount, 10)
+			cpi = formatFloat(r.Feasibility.CostPerInterview)
+			currency = r.Feasibility.Currency
+		}
+		if r.Err != nil {
+			errMsg = r.Err.Error()
+		}
+		details := make([]string, 0, len(r.Quote.DetailedQuote))
+		for _, d := range r.Quote.DetailedQuote {
+			details = append(details, fmt.Sprintf("%s:%s=%s", d.Type, d.Title, formatFloat(d.CostPerUnit)))
+		}
+		var count string
+		if r.Count > 0 {
+			count = strconv.FormatUint(uint64(r.Count), 10)
+		}
+		err = cw.Write([]string{r.CountryISOCode, r.LanguageISOCode, formatFloat(r.Incidence), strconv.FormatInt(r.LOI, 10),
+			count, string(r.Status), strconv.FormatBool(r.Feasible()), totalCount, cpi, currency,
+			formatFloat(r.Quote.CostPerUnit), formatFloat(r.Quote.EstimatedCost), strings.Join(details, ";"), errMsg})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Explorer prices the scenarios of a grid. Each batch of scenarios is added as provisioned line items to a scratch
+// project, created from Project, or to the existing project ExtProjectID. Once their feasibility is known, the
+// scratch projects are closed, or the added line items of the existing project. Nothing is bought.
+type Explorer struct {
+	Client *samplify.Client
+	// Project is the template of the scratch projects. Its line items are ignored.
+	Project *samplify.CreateProjectCriteria
+	// LineItem is the template of the scenarios.
+	LineItem *samplify.CreateLineItemCriteria
+	// ExtProjectID is an existing provisioned project to add the scenarios to, instead of creating scratch projects.
+	ExtProjectID string
+	// IDPrefix prefixes the ids of the scratch projects and line items. It defaults to whatif-<unix time>.
+	IDPrefix string
+	// LineItemsPerProject is the number of scenarios priced at the same time.
+	LineItemsPerProject int
+	// Feasibility configures the polling of the feasibility.
+	Feasibility *samplify.FeasibilityWaitOptions
+	// KeepProjects leaves the scratch projects and line items open.
+	KeepProjects bool
+}
+
+// RunWithContext prices every scenario of the grid and returns their results. Scenarios that could not be priced
+// have their error in the matrix; the returned error is set when the run stopped early, e.g. when the context is done,
+// or when the clean up failed. A template with a quota plan cannot be combined with the locales of other countries
+// or languages.
+func (e *Explorer) RunWithContext(ctx context.Context, grid *Grid) (*Matrix, error) {
+	if e.LineItem == nil {
+		return nil, ErrMissingTemplate
+	}
+	scenarios := grid.Scenarios(e.LineItem)
+	if len(scenarios) == 0 {
+		return nil, ErrNoScenarios
+	}
+	if e.LineItem.QuotaPlan != nil {
+		// Attribute ids and options differ between locales, so the plan cannot be copied to other ones.
+		for _, l := range grid.Locales {
+			if l.CountryISOCode != e.LineItem.CountryISOCode || l.LanguageISOCode != e.LineItem.LanguageISOCode {
+				return nil, fmt.Errorf("%w: %s-%s", ErrQuotaPlanLocale, l.CountryISOCode, l.LanguageISOCode)
+			}
+		}
+	}
+	perProject := e.LineItemsPerProject
+	if perProject <= 0 {
+		perProject = DefaultLineItemsPerProject
+	}
+	prefix := e.IDPrefix
+	if len(prefix) == 0 {
+		prefix = fmt.Sprintf("whatif-%d", time.Now().Unix())
+	}
+
+	m := &Matrix{}
+	for i, s := range scenarios {
+		m.Results = append(m.Results, &Result{Scenario: s, ExtLineItemID: fmt.Sprintf("%s-%d", prefix, i+1)})
+	}
+	for start := 0; start < len(m.Results); start += perProject {
+		end := start + perProject
+		if end > len(m.Results) {
+			end = len(m.Results)
+		}
+		err := e.runBatch(ctx, fmt.Sprintf("%s-p%d", prefix, start/perProject+1), m.Results[start:end])
+		if err != nil {
+			return m, err
+		}
+	}
+	return m, nil
+}
+
+// Run prices every scenario of the grid and returns their results.
+func (e *Explorer) Run(grid *Grid) (*Matrix, error) {
+	return e.RunWithContext(context.Background(), grid)
+}
+
+// runBatch prices a batch of scenarios in one project, then cleans up.
+func (e *Explorer) runBatch(ctx context.Context, scratchID string, results []*Result) (err error) {
+	extProjectID, added := e.ExtProjectID, make([]*Result, 0, len(results))
+	if len(extProjectID) == 0 {
+		if e.Project == nil {
+			return ErrMissingTemplate
+		}
+		project := *e.Project
+		project.ExtProjectID = scratchID
+		project.Title = fmt.Sprintf("%s %s", e.Project.Title, scratchID)
+		project.LineItems = nil
+		for _, r := range results {
+			project.LineItems = append(project.LineItems, e.lineItem(r))
+		}
+		if _, err := e.Client.CreateProjectWithContext(ctx, &project); err != nil {
+			return err
+		}
+		extProjectID, added = scratchID, results
+	} else {
+		for _, r := range results {
+			if _, err := e.Client.AddLineItemWithContext(ctx, extProjectID, e.lineItem(r)); err != nil {
+				r.Err = err
+				continue
+			}
+			added = append(added, r)
+		}
+	}
+	for _, r := range results {
+		r.ExtProjectID = extProjectID
+	}
+	defer func() {
+		if cerr := e.cleanUp(extProjectID, added); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+	if len(added) == 0 {
+		return nil
+	}
+
+	feasibility, err := e.Client.WaitForFeasibilityWithContext(ctx, extProjectID, e.Feasibility)
+	if feasibility != nil {
+		byID := make(map[string]*samplify.LineItemFeasibility, len(feasibility.LineItems))
+		for _, l := range feasibility.LineItems {
+			byID[l.ExtLineItemID] = l
+		}
+		for _, r := range added {
+			l, ok := byID[r.ExtLineItemID]
+			if !ok {
+				continue
+			}
+			r.Status, r.Feasibility, r.Quote, r.Err = l.Status(), l.Feasibility, l.Quote, l.Err
+		}
+	}
+	return err
+}
+
+// cleanUp closes the scratch project, or the line items added to the existing project. It runs even if the context
+// of the run is done.
+func (e *Explorer) cleanUp(extProjectID string, added []*Result) error {
+	if e.KeepProjects || len(added) == 0 {
+		return nil
+	}
+	ctx := context.Background()
+	if len(e.ExtProjectID) == 0 {
+		_, err := e.Client.CloseProjectWithContext(ctx, extProjectID)
+		return err
+	}
+	var msgs []string
+	for _, r := range added {
+		if _, err := e.Client.CloseLineItemWithContext(ctx, extProjectID, r.ExtLineItemID); err != nil {
+			msgs = append(msgs, fmt.Sprintf("line item %s: %v", r.ExtLineItemID, err))
+		}
+	}
+	if len(msgs) > 0 {
+		return fmt.Errorf("closing the scenarios of project %s:\n%s", extProjectID, strings.Join(msgs, "\n"))
+	}
+	return nil
+}
+
+// lineItem returns the line item of a scenario, from the template.
+func (e *Explorer) lineItem(r *Result) *samplify.CreateLineItemCriteria {
+	l := *e.LineItem
+	l.ExtLineItemID = r.ExtLineItemID
+	l.Title = r.Scenario.String()
+	l.CountryISOCode, l.LanguageISOCode = r.CountryISOCode, r.LanguageISOCode
+	l.IndicativeIncidence, l.LengthOfInterview = r.Incidence, r.LOI
+	l.QuotaPlan = e.LineItem.QuotaPlan.Clone()
+	l.Targets = nil
+	for _, t := range e.LineItem.Targets {
+		l.Targets = append(l.Targets, t.Clone())
+	}
+	if r.Count > 0 {
+		count := r.Count
+		l.Targets = []*samplify.LineItemTarget{{Count: &count, Type: samplify.TargetTypeComplete}}
+	}
+	return &l
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
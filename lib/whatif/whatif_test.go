@@ -0,0 +1,178 @@
+package whatif_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	samplify "github.com/morningconsult/go-samplifyapi-client/lib"
+	"github.com/morningconsult/go-samplifyapi-client/lib/whatif"
+)
+
+// fakeAPI prices line items at 100 / incidence per interview, and only finds those with an incidence of 10 or more
+// feasible.
+type fakeAPI struct {
+	mu        sync.Mutex
+	lineItems map[string][]*samplify.CreateLineItemCriteria
+	closed    []string
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.Method == "POST" && len(parts) == 1:
+		var p samplify.CreateProjectCriteria
+		json.NewDecoder(r.Body).Decode(&p)
+		f.lineItems[p.ExtProjectID] = p.LineItems
+		fmt.Fprintf(w, `{"data": {"extProjectId": %q}}`, p.ExtProjectID)
+	case r.Method == "POST" && len(parts) == 3 && parts[2] == "lineItems":
+		var l samplify.CreateLineItemCriteria
+		json.NewDecoder(r.Body).Decode(&l)
+		f.lineItems[parts[1]] = append(f.lineItems[parts[1]], &l)
+		fmt.Fprintf(w, `{"data": {"extLineItemId": %q}}`, l.ExtLineItemID)
+	case r.Method == "GET" && len(parts) == 3 && parts[2] == "feasibility":
+		var data []string
+		for _, l := range f.lineItems[parts[1]] {
+			data = append(data, fmt.Sprintf(`{"extLineItemId": %q, "feasibility": {"status": "READY", "feasible": %t,
+				"costPerInterview": %g, "currency": "USD", "totalCount": %d},
+				"quote": {"costPerUnit": %g, "detailedQuote": [{"type": "BASE", "title": "Base", "costPerUnit": %g}]}}`,
+				l.ExtLineItemID, l.IndicativeIncidence >= 10, 100/l.IndicativeIncidence, *l.Targets[0].Count,
+				100/l.IndicativeIncidence, 100/l.IndicativeIncidence))
+		}
+		fmt.Fprintf(w, `{"data": [%s]}`, strings.Join(data, ","))
+	case r.Method == "POST" && len(parts) == 3 && parts[2] == "close":
+		f.closed = append(f.closed, parts[1])
+		fmt.Fprint(w, `{"data": {}}`)
+	case r.Method == "GET" && len(parts) == 4:
+		fmt.Fprintf(w, `{"data": {"extLineItemId": %q, "state": "PROVISIONED"}}`, parts[3])
+	case r.Method == "POST" && len(parts) == 5 && parts[4] == "close":
+		f.closed = append(f.closed, parts[3])
+		fmt.Fprintf(w, `{"data": {"extLineItemId": %q, "state": "CLOSED"}}`, parts[3])
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newExplorer() (*whatif.Explorer, *fakeAPI, func()) {
+	api := &fakeAPI{lineItems: make(map[string][]*samplify.CreateLineItemCriteria)}
+	ts := httptest.NewServer(api)
+	now := time.Now()
+	client := samplify.NewClient("", "", "", &samplify.ClientOptions{APIBaseURL: ts.URL, AuthURL: ts.URL})
+	client.Auth = samplify.TokenResponse{AccessToken: "test", Acquired: &now, ExpiresIn: 1800}
+
+	surveyURL := "www.mysurvey.com/live/survey"
+	count := uint32(100)
+	e := &whatif.Explorer{
+		Client: client,
+		Project: &samplify.CreateProjectCriteria{
+			Title:              "Pricing",
+			NotificationEmails: []string{"api-test@researchnow.com"},
+			Devices:            []samplify.DeviceType{samplify.DeviceTypeMobile},
+			Category:           &samplify.Category{SurveyTopic: []string{"AUTOMOTIVE"}},
+		},
+		LineItem: &samplify.CreateLineItemCriteria{
+			Title:               "Base",
+			CountryISOCode:      "US",
+			LanguageISOCode:     "en",
+			SurveyURL:           &surveyURL,
+			IndicativeIncidence: 50,
+			DaysInField:         10,
+			LengthOfInterview:   10,
+			Targets:             []*samplify.LineItemTarget{{Count: &count, Type: samplify.TargetTypeComplete}},
+		},
+		IDPrefix:    "wi",
+		Feasibility: &samplify.FeasibilityWaitOptions{InitialInterval: time.Millisecond},
+	}
+	return e, api, ts.Close
+}
+
+func TestExplorerScratchProjects(t *testing.T) {
+	e, api, stop := newExplorer()
+	defer stop()
+	e.LineItemsPerProject = 3
+
+	m, err := e.Run(&whatif.Grid{
+		Locales:    []whatif.Locale{{"US", "en"}, {"GB", "en"}},
+		Incidences: []float64{5, 20},
+		Counts:     []uint32{200},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range m.Results {
+		got = append(got, fmt.Sprintf("%s %s %s %t %g", r.ExtProjectID, r.ExtLineItemID, r.Scenario, r.Feasible(), r.Feasibility.CostPerInterview))
+	}
+	expected := []string{
+		"wi-p1 wi-1 US-en IR 5% LOI 10min N=200 false 20",
+		"wi-p1 wi-2 US-en IR 20% LOI 10min N=200 true 5",
+		"wi-p1 wi-3 GB-en IR 5% LOI 10min N=200 false 20",
+		"wi-p2 wi-4 GB-en IR 20% LOI 10min N=200 true 5",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("got results\n%v\nwant\n%v", got, expected)
+	}
+	if c := m.Cheapest(); c == nil || c.ExtLineItemID != "wi-2" {
+		t.Errorf("got cheapest %+v, want wi-2", c)
+	}
+	sort.Strings(api.closed)
+	if fmt.Sprint(api.closed) != "[wi-p1 wi-p2]" {
+		t.Errorf("got closed projects %v, want [wi-p1 wi-p2]", api.closed)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := m.WriteCSV(buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 || lines[2] != "US,en,20,10,200,READY,true,200,5,USD,5,0,BASE:Base=5," {
+		t.Errorf("unexpected CSV:\n%s", buf.String())
+	}
+}
+
+func TestExplorerExistingProject(t *testing.T) {
+	e, api, stop := newExplorer()
+	defer stop()
+	e.ExtProjectID = "existing"
+
+	m, err := e.Run(&whatif.Grid{LOIs: []int64{10, 25}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Results) != 2 || m.Results[1].LOI != 25 || m.Results[1].Count != 0 || !m.Results[1].Feasible() {
+		t.Errorf("unexpected results %+v", m.Results)
+	}
+	if fmt.Sprint(api.closed) != "[wi-1 wi-2]" {
+		t.Errorf("got closed line items %v, want [wi-1 wi-2]", api.closed)
+	}
+	if len(api.lineItems["existing"]) != 2 || *api.lineItems["existing"][1].Targets[0].Count != 100 {
+		t.Errorf("unexpected line items %+v", api.lineItems["existing"])
+	}
+}
+
+func TestExplorerQuotaPlanLocales(t *testing.T) {
+	e, api, stop := newExplorer()
+	defer stop()
+	e.LineItem.QuotaPlan = &samplify.QuotaPlan{}
+
+	_, err := e.Run(&whatif.Grid{Locales: []whatif.Locale{{"US", "en"}, {"GB", "en"}}})
+	if !errors.Is(err, whatif.ErrQuotaPlanLocale) {
+		t.Errorf("got error %v, want ErrQuotaPlanLocale", err)
+	}
+	if len(api.lineItems) != 0 {
+		t.Errorf("unexpected line items %+v", api.lineItems)
+	}
+	if _, err := e.Run(&whatif.Grid{Locales: []whatif.Locale{{"US", "en"}}, LOIs: []int64{10, 25}}); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}